@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+const (
+	cacheVersion    = 2
+	legacyCacheFile = "metrics.json"
+)
+
+type cacheDocument struct {
+	Version int                     `json:"version"`
+	Player  *runemetrics.PlayerInfo `json:"player"`
+	// Goals were stored in the cache by earlier versions, they are only
+	// read to move them into the goals file
+	Goals []goal `json:"goals,omitempty"`
+}
+
+type cacheMigration func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error)
+
+// cacheMigrations contains the migrations to convert a document of
+// version N (key) into one of version N+1
+var cacheMigrations = map[int]cacheMigration{
+	// Version 0 was the bare PlayerInfo without any wrapping document
+	0: func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		player, err := json.Marshal(doc)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to marshal player info")
+		}
+
+		return map[string]json.RawMessage{"player": player}, nil
+	},
+
+	// Version 1 stored a single target level inside the skills, they
+	// are converted into goals
+	1: func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		var player struct {
+			SkillValues []struct {
+				ID            runemetrics.SkillID      `json:"id"`
+				TargetLevel   int                      `json:"TargetLevel"`
+				TargetMode    runemetrics.ProgressMode `json:"TargetMode"`
+				TargetStartXP int64                    `json:"TargetStartXP"`
+			} `json:"skillvalues"`
+		}
+
+		if p, ok := doc["player"]; ok {
+			if err := json.Unmarshal(p, &player); err != nil {
+				return nil, errors.Wrap(err, "Unable to decode player info")
+			}
+		}
+
+		goals := []goal{}
+		for _, sk := range player.SkillValues {
+			if sk.TargetLevel == 0 {
+				continue
+			}
+
+			goals = append(goals, goal{
+				ID:      len(goals) + 1,
+				Kind:    goalLevel,
+				Skills:  []runemetrics.SkillID{sk.ID},
+				Target:  int64(sk.TargetLevel),
+				Mode:    sk.TargetMode,
+				Created: time.Now(),
+				StartXP: map[runemetrics.SkillID]int64{sk.ID: sk.TargetStartXP},
+			})
+		}
+
+		raw, err := json.Marshal(goals)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to marshal goals")
+		}
+
+		doc["goals"] = raw
+		return doc, nil
+	},
+}
+
+// errCacheCorrupt signals the cache file exists but cannot be decoded
+var errCacheCorrupt = errors.New("Cache file is corrupt")
+
+func getCacheDir() (string, error) {
+	if cfg.CacheDir != "" {
+		return cfg.CacheDir, nil
+	}
+
+	return getDefaultCacheDir()
+}
+
+// getDefaultCacheDir returns the cache dir used without --cache-dir,
+// this is where earlier versions stored their cache
+func getDefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to retrieve user cache dir")
+	}
+
+	return path.Join(cacheDir, "luzifer", "runemetrics"), nil
+}
+
+func getCacheFile(player string) (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(cacheDir, runemetrics.NormalizePlayerName(player)+".json"), nil
+}
+
+func storeCache(player string, p *runemetrics.PlayerInfo) error {
+	cacheFile, err := getCacheFile(player)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(cacheFile, cacheDocument{Version: cacheVersion, Player: p})
+}
+
+func loadCache(player string) (*cacheDocument, error) {
+	if err := migrateLegacyCache(); err != nil {
+		return nil, errors.Wrap(err, "Unable to migrate legacy cache")
+	}
+
+	cacheFile, err := getCacheFile(player)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(cacheFile); err != nil {
+		if os.IsNotExist(err) {
+			// Empty cache
+			return &cacheDocument{}, nil
+		}
+		return nil, errors.Wrap(err, "Unable to stat cache file")
+	}
+
+	doc, err := readCacheFile(cacheFile)
+	if errors.Cause(err) == errCacheCorrupt {
+		log.WithError(err).Warn("Unable to read cache, starting with an empty one")
+		return &cacheDocument{}, backupCacheFile(cacheFile)
+	}
+
+	return doc, err
+}
+
+// migrateLegacyCache moves the single cache file used by earlier
+// versions (always located in the default cache dir) to the per-player
+// cache file of the player stored in it
+func migrateLegacyCache() error {
+	cacheDir, err := getDefaultCacheDir()
+	if err != nil {
+		return err
+	}
+
+	legacyFile := path.Join(cacheDir, legacyCacheFile)
+	if _, err := os.Stat(legacyFile); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "Unable to stat legacy cache file")
+	}
+
+	doc, err := readCacheFile(legacyFile)
+	switch {
+	case errors.Cause(err) == errCacheCorrupt:
+		log.WithError(err).Warn("Unable to read legacy cache, not migrating")
+		return backupCacheFile(legacyFile)
+
+	case err != nil:
+		return err
+	}
+
+	if doc.Player == nil || doc.Player.Name == "" {
+		// We don't know whom the cache belongs to, nothing to migrate
+		return errors.Wrap(os.Remove(legacyFile), "Unable to remove legacy cache file")
+	}
+
+	cacheFile, err := getCacheFile(doc.Player.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(cacheFile); err == nil {
+		// Player already has a newer cache, legacy one is outdated
+		return errors.Wrap(os.Remove(legacyFile), "Unable to remove legacy cache file")
+	}
+
+	log.WithField("player", doc.Player.Name).Info("Migrating legacy cache file")
+
+	// The cache dir might be on another filesystem, copy the contents
+	// instead of renaming the file
+	raw, err := ioutil.ReadFile(legacyFile)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read legacy cache file")
+	}
+
+	if err = writeFileAtomic(cacheFile, json.RawMessage(raw)); err != nil {
+		return errors.Wrap(err, "Unable to write migrated cache file")
+	}
+
+	return errors.Wrap(os.Remove(legacyFile), "Unable to remove legacy cache file")
+}
+
+// writeFileAtomic writes the JSON representation of the value into a
+// temporary file first and moves it in place afterwards to never leave
+// a partially written file behind
+func writeFileAtomic(file string, v interface{}) error {
+	if err := os.MkdirAll(path.Dir(file), 0755); err != nil {
+		return errors.Wrap(err, "Unable to create directory")
+	}
+
+	f, err := ioutil.TempFile(path.Dir(file), "."+path.Base(file)+".*")
+	if err != nil {
+		return errors.Wrap(err, "Unable to create temporary file")
+	}
+	defer os.Remove(f.Name()) // Fails after successful rename, that's fine
+
+	if err = json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Unable to marshal into file")
+	}
+
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "Unable to flush file")
+	}
+
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "Unable to close file")
+	}
+
+	return errors.Wrap(os.Rename(f.Name(), file), "Unable to move file in place")
+}
+
+// backupCacheFile moves an unreadable cache file out of the way so a
+// fresh cache can be started without losing the old data
+func backupCacheFile(cacheFile string) error {
+	backupFile := fmt.Sprintf("%s.%d.bak", cacheFile, time.Now().Unix())
+
+	log.WithField("backup", backupFile).Warn("Moving unreadable cache file to backup")
+	return errors.Wrap(os.Rename(cacheFile, backupFile), "Unable to backup cache file")
+}
+
+func readCacheFile(cacheFile string) (*cacheDocument, error) {
+	raw, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to read cache file")
+	}
+
+	var doc map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrap(errCacheCorrupt, err.Error())
+	}
+
+	var version int
+	if v, ok := doc["version"]; ok {
+		if err = json.Unmarshal(v, &version); err != nil {
+			return nil, errors.Wrap(errCacheCorrupt, "Unable to decode version")
+		}
+	}
+
+	if version > cacheVersion {
+		return nil, errors.Errorf("Cache file was written by a newer version (%d > %d)", version, cacheVersion)
+	}
+
+	for ; version < cacheVersion; version++ {
+		if doc, err = cacheMigrations[version](doc); err != nil {
+			return nil, errors.Wrapf(errCacheCorrupt, "Unable to migrate cache from version %d: %s", version, err)
+		}
+	}
+
+	if raw, err = json.Marshal(doc); err != nil {
+		return nil, errors.Wrap(err, "Unable to marshal migrated cache")
+	}
+
+	out := &cacheDocument{}
+	if err = json.Unmarshal(raw, out); err != nil {
+		return nil, errors.Wrap(errCacheCorrupt, err.Error())
+	}
+
+	return out, nil
+}
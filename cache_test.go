@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateLegacyCacheWithCacheDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "runemetrics-cache")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	defer func(xdg, cacheDir string) {
+		os.Setenv("XDG_CACHE_HOME", xdg)
+		cfg.CacheDir = cacheDir
+	}(os.Getenv("XDG_CACHE_HOME"), cfg.CacheDir)
+
+	os.Setenv("XDG_CACHE_HOME", path.Join(tmp, "default"))
+	cfg.CacheDir = path.Join(tmp, "custom")
+
+	// Earlier versions stored the bare profile in the default cache dir
+	legacyDir := path.Join(tmp, "default", "luzifer", "runemetrics")
+	if err = os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatalf("Unable to create legacy cache dir: %s", err)
+	}
+	legacy := `{"name":"Zezima","skillvalues":[{"id":0,"level":90,"xp":56330120,"TargetLevel":99}]}`
+	if err = ioutil.WriteFile(path.Join(legacyDir, legacyCacheFile), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Unable to write legacy cache: %s", err)
+	}
+
+	doc, err := loadCache("zezima")
+	if err != nil {
+		t.Fatalf("Unable to load cache: %s", err)
+	}
+
+	if doc.Player == nil || doc.Player.Name != "Zezima" {
+		t.Fatalf("Player = %+v, want migrated profile of Zezima", doc.Player)
+	}
+
+	if len(doc.Goals) != 1 || doc.Goals[0].Target != 99 {
+		t.Errorf("Goals = %+v, want the migrated target level", doc.Goals)
+	}
+
+	if _, err = os.Stat(path.Join(cfg.CacheDir, "zezima.json")); err != nil {
+		t.Errorf("Cache was not written into --cache-dir: %s", err)
+	}
+
+	if _, err = os.Stat(path.Join(legacyDir, legacyCacheFile)); !os.IsNotExist(err) {
+		t.Errorf("Legacy cache file was not removed: %v", err)
+	}
+}
+
+func TestLoadCacheCorrupt(t *testing.T) {
+	for _, tc := range []struct {
+		Name    string
+		Content string
+	}{
+		{Name: "invalid json", Content: `{"player":`},
+		{Name: "bare profile", Content: `{"skillvalues":5}`},
+		{Name: "version 1", Content: `{"version":1,"player":{"skillvalues":5}}`},
+		{Name: "current version", Content: `{"version":2,"player":{"skillvalues":5}}`},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			tmp, err := ioutil.TempDir("", "runemetrics-cache")
+			if err != nil {
+				t.Fatalf("Unable to create temp dir: %s", err)
+			}
+			defer os.RemoveAll(tmp)
+
+			defer func(xdg, cacheDir string) {
+				os.Setenv("XDG_CACHE_HOME", xdg)
+				cfg.CacheDir = cacheDir
+			}(os.Getenv("XDG_CACHE_HOME"), cfg.CacheDir)
+
+			os.Setenv("XDG_CACHE_HOME", tmp)
+			cfg.CacheDir = tmp
+
+			cacheFile := path.Join(tmp, "zezima.json")
+			if err = ioutil.WriteFile(cacheFile, []byte(tc.Content), 0644); err != nil {
+				t.Fatalf("Unable to write cache: %s", err)
+			}
+
+			doc, err := loadCache("zezima")
+			if err != nil {
+				t.Fatalf("loadCache() = %v, want empty cache", err)
+			}
+			if doc.Player != nil || len(doc.Goals) != 0 {
+				t.Errorf("loadCache() = %+v, want empty cache", doc)
+			}
+
+			if _, err = os.Stat(cacheFile); !os.IsNotExist(err) {
+				t.Errorf("Corrupt cache file was not moved: %v", err)
+			}
+
+			backups, err := filepath.Glob(cacheFile + ".*.bak")
+			if err != nil || len(backups) != 1 {
+				t.Errorf("backups = %v (%v), want one backup file", backups, err)
+			}
+		})
+	}
+}
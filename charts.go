@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strconv"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type chartRange int
+
+const (
+	chartRangeDay chartRange = iota
+	chartRangeWeek
+	chartRangeMonth
+	chartRangeCount // Keep last, used to cycle
+)
+
+var (
+	selectedChartRange = chartRangeDay
+
+	// chartCache prevents querying the history on every render
+	chartCache struct {
+		key  string
+		data []float64
+	}
+)
+
+func (c chartRange) Duration() time.Duration {
+	switch c {
+	case chartRangeWeek:
+		return 7 * 24 * time.Hour
+	case chartRangeMonth:
+		return 30 * 24 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+func (c chartRange) Label() string {
+	switch c {
+	case chartRangeWeek:
+		return "last week"
+	case chartRangeMonth:
+		return "last month"
+	default:
+		return "last day"
+	}
+}
+
+func (c chartRange) Next() chartRange {
+	return (c + 1) % chartRangeCount
+}
+
+func renderCharts(t *trackedPlayer, area image.Rectangle) {
+	var (
+		left  = image.Rect(area.Min.X, area.Min.Y, area.Min.X+area.Dx()/2, area.Max.Y)
+		right = image.Rect(left.Max.X, area.Min.Y, area.Max.X, area.Max.Y)
+	)
+
+	renderSkillPlot(t, left)
+	renderSessionBars(t, right)
+}
+
+// renderSkillPlot shows the XP gained in the selected skill over the
+// selected chart range
+func renderSkillPlot(t *trackedPlayer, area image.Rectangle) {
+	if selectedMetric >= len(t.Data.SkillValues) {
+		return
+	}
+
+	var (
+		skill  = t.Data.SkillValues[selectedMetric].ID
+		points = area.Dx() - 2
+	)
+
+	plot := widgets.NewPlot()
+	plot.ShowAxes = false
+	plot.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+
+	data, err := t.skillXPSeries(skill, selectedChartRange, points)
+	if err != nil {
+		log.WithError(err).Error("Unable to load chart data")
+	}
+
+	if len(data) < 2 {
+		p := widgets.NewParagraph()
+		p.Title = fmt.Sprintf("%s XP (%s)", skill, selectedChartRange.Label())
+		p.Text = "No history available"
+		p.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+		ui.Render(p)
+		return
+	}
+
+	plot.Title = fmt.Sprintf("%s XP gained (%s): %.0f", skill, selectedChartRange.Label(), data[len(data)-1])
+	plot.Data = [][]float64{data}
+	if c, ok := skillColors[skill]; ok {
+		plot.LineColors = []ui.Color{c}
+	}
+	if data[len(data)-1] <= 0 {
+		// Nothing gained, prevent a division by zero in the plot
+		plot.MaxVal = 1
+	}
+
+	ui.Render(plot)
+}
+
+// renderSessionBars compares the XP gained per skill in the latest
+// play session
+func renderSessionBars(t *trackedPlayer, area image.Rectangle) {
+	bars := widgets.NewBarChart()
+	bars.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+	bars.BarWidth = 4
+	bars.NumFormatter = func(v float64) string { return shortNumber(int64(v)) }
+
+	session, _ := t.Sessions.Latest(time.Now())
+	if session == nil || session.TotalGained() == 0 {
+		p := widgets.NewParagraph()
+		p.Title = "Session XP per skill"
+		p.Text = "No session detected yet"
+		p.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+		ui.Render(p)
+		return
+	}
+
+	var skills []runemetrics.SkillID
+	for id, gained := range session.Gained {
+		if gained > 0 {
+			skills = append(skills, id)
+		}
+	}
+	sort.Slice(skills, func(i, j int) bool { return session.Gained[skills[i]] > session.Gained[skills[j]] })
+
+	bars.Title = "Session XP per skill"
+	for _, id := range skills {
+		bars.Data = append(bars.Data, float64(session.Gained[id]/10))
+		bars.Labels = append(bars.Labels, shortSkillName(id))
+
+		if c, ok := skillColors[id]; ok {
+			bars.BarColors = append(bars.BarColors, c)
+		}
+	}
+
+	if len(bars.BarColors) != len(bars.Data) {
+		// Not all skills have colors, use the default colors
+		bars.BarColors = widgets.NewBarChart().BarColors
+	}
+
+	ui.Render(bars)
+}
+
+// skillXPSeries returns the XP gained in the skill over the given range
+// sampled into the given number of equally sized time buckets
+func (t *trackedPlayer) skillXPSeries(skill runemetrics.SkillID, r chartRange, points int) ([]float64, error) {
+	if t.History == nil || points < 2 {
+		return nil, nil
+	}
+
+	var (
+		now   = time.Now()
+		start = now.Add(-r.Duration())
+		key   = fmt.Sprintf("%s:%d:%d:%d:%d", t.Name, skill, r, points, t.getLastUpdate(updateKeyGeneral).UnixNano())
+	)
+
+	if chartCache.key == key {
+		return chartCache.data, nil
+	}
+
+	base, err := t.History.Baseline(start)
+	if err != nil || base == nil {
+		return nil, err
+	}
+
+	snaps, err := t.History.Snapshots(start, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		baseXP  = base.Skill(skill).XP
+		current = baseXP
+		data    = make([]float64, points)
+		idx     int
+		width   = r.Duration() / time.Duration(points)
+	)
+
+	for b := range data {
+		end := start.Add(time.Duration(b+1) * width)
+		for ; idx < len(snaps) && !snaps[idx].Time.After(end); idx++ {
+			current = snaps[idx].Skill(skill).XP
+		}
+		data[b] = float64(current-baseXP) / 10
+	}
+
+	chartCache.key = key
+	chartCache.data = data
+
+	return data, nil
+}
+
+// shortNumber formats large numbers with a k / M suffix
+func shortNumber(v int64) string {
+	switch {
+	case v >= 10000000:
+		return fmt.Sprintf("%dM", v/1000000)
+	case v >= 10000:
+		return fmt.Sprintf("%dk", v/1000)
+	default:
+		return fmt.Sprintf("%d", v)
+	}
+}
+
+// shortSkillName abbreviates the skill name to fit a bar label, skills
+// not known yet are labelled by their ID
+func shortSkillName(id runemetrics.SkillID) string {
+	name := id.String()
+	if name == "" {
+		return strconv.FormatUint(uint64(id), 10)
+	}
+
+	if r := []rune(name); len(r) > 3 {
+		return string(r[:3])
+	}
+	return name
+}
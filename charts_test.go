@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func TestShortSkillName(t *testing.T) {
+	for id, want := range map[runemetrics.SkillID]string{
+		0:   "Att",
+		26:  "Inv",
+		27:  "27",
+		999: "999",
+	} {
+		if got := shortSkillName(id); got != want {
+			t.Errorf("shortSkillName(%d) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Command fake-runemetrics is an offline stand-in for the RuneMetrics
+// profile API serving recorded profile fixtures
+package main
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/rconfig/v2"
+)
+
+var cfg = struct {
+	ErrorRate float64 `flag:"error-rate" default:"0" description:"Fraction of requests to answer with a rate-limit or server error"`
+	Fixtures  string  `flag:"fixtures" default:"fixtures" description:"Directory containing the <player>.json profile fixtures"`
+	Listen    string  `flag:"listen" default:":3000" description:"Port/IP to listen on"`
+	LogLevel  string  `flag:"log-level" default:"info" description:"Log level (debug, info, warn, error, fatal)"`
+	XPGain    int64   `flag:"xp-gain" default:"0" description:"Simulate XP gains of up to this amount per request (0 = disabled)"`
+}{}
+
+func init() {
+	if err := rconfig.ParseAndValidate(&cfg); err != nil {
+		log.Fatalf("Unable to parse commandline options: %s", err)
+	}
+
+	if l, err := log.ParseLevel(cfg.LogLevel); err != nil {
+		log.WithError(err).Fatal("Unable to parse log level")
+	} else {
+		log.SetLevel(l)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+}
+
+func main() {
+	log.WithFields(log.Fields{
+		"fixtures": cfg.Fixtures,
+		"listen":   cfg.Listen,
+	}).Info("Fake RuneMetrics API started")
+
+	if err := http.ListenAndServe(cfg.Listen, newFakeServer(cfg.Fixtures, cfg.XPGain, cfg.ErrorRate)); err != nil {
+		log.WithError(err).Fatal("HTTP server exited")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type apiError struct {
+	Error    string `json:"error"`
+	LoggedIn string `json:"loggedIn"`
+}
+
+type fakeServer struct {
+	errorRate  float64
+	fixtureDir string
+	xpGain     int64
+
+	players map[string]*runemetrics.PlayerInfo
+	lock    sync.Mutex
+}
+
+func newFakeServer(fixtureDir string, xpGain int64, errorRate float64) *fakeServer {
+	return &fakeServer{
+		errorRate:  errorRate,
+		fixtureDir: fixtureDir,
+		xpGain:     xpGain,
+		players:    map[string]*runemetrics.PlayerInfo{},
+	}
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/profile/profile":
+		f.serveProfile(w, r)
+	case "/webhook":
+		f.serveWebhook(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeServer) serveProfile(w http.ResponseWriter, r *http.Request) {
+
+	var (
+		name          = r.FormValue("user")
+		activities, _ = strconv.Atoi(r.FormValue("activities"))
+	)
+
+	logger := log.WithField("user", name)
+
+	if rand.Float64() < f.errorRate {
+		status := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}[rand.Intn(2)]
+		logger.WithField("status", status).Debug("Simulating error")
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	p, raw, err := f.loadPlayer(name)
+	switch {
+
+	case err != nil:
+		logger.WithError(err).Error("Unable to load fixture")
+		http.Error(w, "Unable to load fixture", http.StatusInternalServerError)
+		return
+
+	case raw != nil:
+		// Fixture is not a profile (i.e. an error payload), serve it as-is
+		logger.Debug("Serving raw fixture")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(raw)
+		return
+
+	case p == nil:
+		logger.Debug("No fixture found")
+		f.writeJSON(w, apiError{Error: "NO_PROFILE", LoggedIn: "false"})
+		return
+
+	}
+
+	if f.xpGain > 0 {
+		f.simulateGain(p)
+	}
+
+	out := *p
+	if activities >= 0 && activities < len(out.Activities) {
+		out.Activities = out.Activities[:activities]
+	}
+
+	logger.Debug("Serving profile")
+	f.writeJSON(w, out)
+}
+
+// serveWebhook logs the payloads posted to it to act as a receiver for
+// notification webhooks
+func (f *fakeServer) serveWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.WithError(err).Error("Unable to read webhook payload")
+		http.Error(w, "Unable to read payload", http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("payload", string(body)).Info("Webhook received")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeServer) loadPlayer(name string) (*runemetrics.PlayerInfo, []byte, error) {
+	key := runemetrics.NormalizePlayerName(name)
+
+	if p, ok := f.players[key]; ok {
+		return p, nil, nil
+	}
+
+	raw, err := ioutil.ReadFile(path.Join(f.fixtureDir, key+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrap(err, "Unable to read fixture")
+	}
+
+	var probe apiError
+	if err = json.Unmarshal(raw, &probe); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to decode fixture")
+	}
+
+	if probe.Error != "" {
+		return nil, raw, nil
+	}
+
+	p := &runemetrics.PlayerInfo{}
+	if err = json.Unmarshal(raw, p); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to decode fixture")
+	}
+
+	f.players[key] = p
+	return p, nil, nil
+}
+
+// simulateGain adds a random amount of XP to a random skill of the
+// player and logs an activity in case the skill levelled up
+func (f *fakeServer) simulateGain(p *runemetrics.PlayerInfo) {
+	if len(p.SkillValues) == 0 {
+		return
+	}
+
+	var (
+		idx  = rand.Intn(len(p.SkillValues))
+		sk   = &p.SkillValues[idx]
+		gain = rand.Int63n(f.xpGain) + 1
+		info = sk.ID.Info()
+	)
+
+	sk.XP += gain * 10
+	p.TotalXP += gain
+
+	newLevel := info.LevelFromXP(sk.XP / 10)
+	if newLevel <= sk.Level {
+		return
+	}
+
+	p.TotalSkill += int64(newLevel - sk.Level)
+	sk.Level = newLevel
+
+	p.Activities = append([]runemetrics.Activity{{
+		Date:    time.Now().In(londonTime()).Format("02-Jan-2006 15:04"),
+		Details: fmt.Sprintf("I levelled my %s skill, I am now level %d.", info.Name, newLevel),
+		Text:    fmt.Sprintf("Levelled Up %s.", info.Name),
+	}}, p.Activities...)
+}
+
+func (f *fakeServer) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Error("Unable to encode response")
+	}
+}
+
+func londonTime() *time.Location {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	ui "github.com/gizak/termui/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+const (
+	skillColorsAlways = "always"
+	skillColorsAuto   = "auto"
+	skillColorsNever  = "never"
+)
+
+// cubeLevels are the channel values of the 6x6x6 color cube within
+// the xterm 256 color palette (indices 16-231)
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+var skillColors = map[runemetrics.SkillID]ui.Color{}
+
+// initSkillColors maps the skill colors to the nearest terminal colors
+// and registers them for use in styled text as "fg:skill<id>"
+func initSkillColors() {
+	if !skillColorsEnabled() {
+		return
+	}
+
+	for _, s := range runemetrics.SkillList {
+		c, err := nearestTermColor(s.Color)
+		if err != nil {
+			log.WithError(err).WithField("skill", s.Name).Debug("Unable to parse skill color")
+			continue
+		}
+
+		skillColors[runemetrics.SkillID(s.ID)] = c
+		ui.StyleParserColorMap[fmt.Sprintf("skill%d", s.ID)] = c
+	}
+}
+
+// skillColorsEnabled checks the config and whether the terminal is
+// able to display 256 colors (truecolor terminals are able to)
+func skillColorsEnabled() bool {
+	switch cfg.SkillColors {
+	case skillColorsAlways:
+		return true
+	case skillColorsNever:
+		return false
+	}
+
+	switch os.Getenv("COLORTERM") {
+	case "truecolor", "24bit":
+		return true
+	}
+
+	return strings.Contains(os.Getenv("TERM"), "256color")
+}
+
+// coloredSkillName returns the name of the skill marked up to be
+// rendered in the skill color (if available)
+func coloredSkillName(id runemetrics.SkillID) string {
+	if _, ok := skillColors[id]; !ok {
+		return id.String()
+	}
+	return fmt.Sprintf("[%s](fg:skill%d)", id, id)
+}
+
+// nearestTermColor finds the xterm 256 palette color closest to the
+// given hex color (#rrggbb)
+func nearestTermColor(hex string) (ui.Color, error) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return ui.ColorClear, fmt.Errorf("Invalid color %q", hex)
+	}
+
+	rgb, err := strconv.ParseUint(hex[1:], 16, 32)
+	if err != nil {
+		return ui.ColorClear, fmt.Errorf("Invalid color %q", hex)
+	}
+
+	var (
+		r = int(rgb >> 16 & 0xff)
+		g = int(rgb >> 8 & 0xff)
+		b = int(rgb & 0xff)
+
+		best     = ui.ColorClear
+		bestDist = -1
+	)
+
+	check := func(c ui.Color, cr, cg, cb int) {
+		dist := (r-cr)*(r-cr) + (g-cg)*(g-cg) + (b-cb)*(b-cb)
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+
+	for ri, cr := range cubeLevels {
+		for gi, cg := range cubeLevels {
+			for bi, cb := range cubeLevels {
+				check(ui.Color(16+36*ri+6*gi+bi), cr, cg, cb)
+			}
+		}
+	}
+
+	for i := 0; i < 24; i++ {
+		// Grayscale ramp at indices 232-255
+		v := 8 + 10*i
+		check(ui.Color(232+i), v, v, v)
+	}
+
+	return best, nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/rconfig/v2"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+const watchTimeFormat = "2006-01-02 15:04:05"
+
+type command struct {
+	Args        string
+	Description string
+	Run         func(args []string) error
+}
+
+// errUsage signals the command was called with invalid arguments
+var errUsage = errors.New("Invalid arguments")
+
+var commands = map[string]command{
+	"compare": {Args: "<player> <player>", Description: "Print the profiles of two players side by side", Run: runCompare},
+	"goal":    {Args: "<set|add|list|clear> <player> ...", Description: "Manage the goals of the player", Run: runGoalCommand},
+	"json":    {Args: "<player>", Description: "Print the profile as JSON", Run: runJSON},
+	"serve":   {Args: "<player> [<player>...]", Description: "Expose the profiles as Prometheus metrics", Run: runServe},
+	"show":    {Args: "<player>", Description: "Print the profile as a table", Run: runShow},
+	"tui":     {Args: "<player> [<player>...]", Description: "Start the interactive terminal UI (default)", Run: runTUI},
+	"version": {Description: "Print the version", Run: runVersion},
+	"watch":   {Args: "<player>", Description: "Print a line for every change on the update schedule", Run: runWatch},
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: runemetrics [options] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "       runemetrics [options] <player>")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	w := tabwriter.NewWriter(os.Stderr, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s %s\t%s\n", name, commands[name].Args, commands[name].Description)
+	}
+	w.Flush()
+
+	fmt.Fprintln(os.Stderr)
+	rconfig.Usage()
+}
+
+func printVersion() {
+	fmt.Printf("runemetrics %s\n", version)
+}
+
+// fetchOnce loads the player, fetches the current profile and closes
+// the history again
+func fetchOnce(player string) (*trackedPlayer, error) {
+	t, err := loadPlayer(player)
+	if err != nil {
+		return nil, err
+	}
+
+	t.openHistory()
+	defer t.Close()
+
+	if t.Data, err = t.fetch(); err != nil {
+		return nil, errors.Wrap(err, "Unable to fetch player info")
+	}
+
+	return t, nil
+}
+
+func runJSON(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	t, err := fetchOnce(args[0])
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(t.Data), "Unable to encode player info")
+}
+
+func runShow(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	t, err := fetchOnce(args[0])
+	if err != nil {
+		return err
+	}
+
+	p := t.Data
+	fmt.Printf("%s: combat level %d, total level %d, total XP %d, rank %d\n\n",
+		p.Name, p.CombatLevel, p.TotalSkill, p.TotalXP, p.NumericRank())
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Skill\tLevel\tLevel %\tCurrent XP\tXP remaining\tTo Level\t")
+
+	for _, s := range p.SkillValues {
+		row := t.newLevelRow(s)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t\n",
+			s.ID, row.Level, row.Percentage, s.XP/10, row.Remaining, row.Target)
+	}
+
+	if err = w.Flush(); err != nil {
+		return errors.Wrap(err, "Unable to write table")
+	}
+
+	fmt.Printf("\nRecent activities: %s\n", eventSummary(countEvents(p.Activities)))
+	return nil
+}
+
+func runVersion(args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	printVersion()
+	return nil
+}
+
+// runWatch fetches the player on the update schedule and prints every
+// change as a line to stdout
+func runWatch(args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	t, err := loadPlayer(args[0])
+	if err != nil {
+		return err
+	}
+
+	t.openHistory()
+	defer t.Close()
+
+	var (
+		cron = cronexpr.MustParse(cfg.Update)
+		prev *runemetrics.PlayerInfo
+	)
+
+	for {
+		p, err := t.fetch()
+		nextUpdate := time.Until(cron.Next(time.Now()))
+
+		switch {
+
+		case err == nil:
+			t.FetchRetry.reset()
+			printChanges(os.Stdout, prev, p)
+			sendNotifications(detectNotifications(prev, p))
+			prev = p
+
+			if completed := t.completeGoals(p); len(completed) > 0 {
+				for _, g := range completed {
+					fmt.Printf("%s %s: goal completed: %s\n", time.Now().Format(watchTimeFormat), p.Name, g)
+				}
+				sendNotifications(goalNotifications(p.Name, completed))
+				if err := t.saveGoals(); err != nil {
+					log.WithError(err).Error("Unable to store goals")
+				}
+			}
+
+		case runemetrics.IsTemporary(err):
+			log.WithError(err).Error("Unable to fetch metrics")
+			if delay, ok := t.FetchRetry.next(); ok && delay < nextUpdate {
+				nextUpdate = delay
+			} else {
+				t.FetchRetry.reset()
+			}
+
+		default:
+			log.WithError(err).Error("Unable to fetch metrics")
+			t.FetchRetry.reset()
+
+		}
+
+		time.Sleep(nextUpdate)
+	}
+}
+
+// printChanges writes one line for every change between the two states
+// of the player, without a previous state the current one is summarized
+func printChanges(w io.Writer, prev, p *runemetrics.PlayerInfo) {
+	now := time.Now().Format(watchTimeFormat)
+
+	if prev == nil {
+		fmt.Fprintf(w, "%s %s: combat level %d, total level %d, total XP %d\n",
+			now, p.Name, p.CombatLevel, p.TotalSkill, p.TotalXP)
+		return
+	}
+
+	if prev.LoggedIn != p.LoggedIn {
+		state := "logged out"
+		if p.LoggedIn {
+			state = "logged in"
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", now, p.Name, state)
+	}
+
+	for _, s := range p.SkillValues {
+		o := prev.GetSkill(s.ID)
+
+		if s.XP > o.XP {
+			fmt.Fprintf(w, "%s %s: %s +%d XP (%d XP)\n", now, p.Name, s.ID, (s.XP-o.XP)/10, s.XP/10)
+		}
+
+		if s.Level > o.Level {
+			fmt.Fprintf(w, "%s %s: %s level %d\n", now, p.Name, s.ID, s.Level)
+		}
+	}
+
+	if p.QuestsComplete > prev.QuestsComplete {
+		fmt.Fprintf(w, "%s %s: quests complete %d\n", now, p.Name, p.QuestsComplete)
+	}
+
+	for _, a := range newActivities(prev, p) {
+		date, _ := a.GetParsedDate()
+		fmt.Fprintf(w, "%s %s: %s\n", date.Local().Format(watchTimeFormat), p.Name, a.Text)
+	}
+}
+
+// newActivities returns the activities of the player not yet known in
+// the previous state in chronological order
+func newActivities(prev, p *runemetrics.PlayerInfo) []runemetrics.Activity {
+	var out []runemetrics.Activity
+
+	for _, a := range p.Activities {
+		if len(prev.Activities) > 0 && a.Details == prev.Activities[0].Details {
+			break
+		}
+		out = append([]runemetrics.Activity{a}, out...)
+	}
+
+	return out
+}
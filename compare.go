@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/pkg/errors"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// comparePlayer is the player the active player is compared with in
+// the UI, nil when the levels are shown
+var comparePlayer *trackedPlayer
+
+// skillComparison contains the state of one skill of two players
+type skillComparison struct {
+	ID   runemetrics.SkillID
+	A, B runemetrics.Skill
+}
+
+func compareSkills(a, b *runemetrics.PlayerInfo) []skillComparison {
+	var out []skillComparison
+	for _, s := range a.SkillValues {
+		out = append(out, skillComparison{ID: s.ID, A: s, B: b.GetSkill(s.ID)})
+	}
+	return out
+}
+
+// LevelDelta returns by how many levels A is ahead of B
+func (c skillComparison) LevelDelta() int64 {
+	return int64(skillLevel(c.A) - skillLevel(c.B))
+}
+
+// XPDelta returns by how much XP A is ahead of B
+func (c skillComparison) XPDelta() int64 {
+	return (c.A.XP - c.B.XP) / 10
+}
+
+// RankDelta returns by how many ranks A is ahead of B, players without
+// a rank in the skill cannot be compared
+func (c skillComparison) RankDelta() (int64, bool) {
+	return rankDelta(c.A.Rank, c.B.Rank)
+}
+
+// rankDelta returns by how many ranks a is ahead of b
+func rankDelta(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+	return b - a, true
+}
+
+// skillLevel returns the level of the skill respecting the configuration
+// whether to use virtual levels
+func skillLevel(s runemetrics.Skill) int {
+	if cfg.VirtualLevels {
+		return skillInfo(s.ID).LevelFromXP(s.XP / 10)
+	}
+	return s.Level
+}
+
+func formatDelta(d int64) string {
+	if d > 0 {
+		return "+" + strconv.FormatInt(d, 10)
+	}
+	return strconv.FormatInt(d, 10)
+}
+
+func formatRank(r int64) string {
+	if r == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(r, 10)
+}
+
+// highlightDelta right-aligns the delta and colors it by which player
+// is ahead
+func highlightDelta(d int64, width int) string {
+	switch {
+	case d > 0:
+		return fmt.Sprintf("[%*s](fg:green)", width, formatDelta(d))
+	case d < 0:
+		return fmt.Sprintf("[%*s](fg:red)", width, formatDelta(d))
+	default:
+		return fmt.Sprintf("%*s", width, formatDelta(d))
+	}
+}
+
+// cycleComparePlayer compares the active player with the next tracked
+// player, after the last one the comparison is disabled again
+func cycleComparePlayer() {
+	var candidates []*trackedPlayer
+	for _, t := range trackedPlayers {
+		if t != activePlayer {
+			candidates = append(candidates, t)
+		}
+	}
+
+	next := 0
+	for i, t := range candidates {
+		if t == comparePlayer {
+			next = i + 1
+		}
+	}
+
+	comparePlayer = nil
+	if next < len(candidates) {
+		comparePlayer = candidates[next]
+	}
+}
+
+// comparedPlayer returns the player to compare the given one with or
+// nil if there is nothing to compare with
+func comparedPlayer(t *trackedPlayer) *trackedPlayer {
+	if comparePlayer == nil || comparePlayer == t || comparePlayer.Data == nil {
+		return nil
+	}
+	return comparePlayer
+}
+
+func renderComparison(a, b *runemetrics.PlayerInfo, area image.Rectangle) {
+	table := widgets.NewTable()
+	table.Title = fmt.Sprintf("Compare (A: %s, B: %s, v: next player)", a.Name, b.Name)
+	table.RowStyles[0] = ui.Style{Fg: ui.ColorWhite, Modifier: ui.ModifierBold}
+	table.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+	table.RowSeparator = false
+
+	table.ColumnWidths = []int{area.Dx() - 2 - 9 - 7 - 7 - 6 - 11 - 11 - 11 - 9 - 9 - 9, 7, 7, 6, 11, 11, 11, 9, 9, 9}
+
+	table.Rows = [][]string{{
+		"  Skill",
+		fmt.Sprintf("%*s", 7, "Level A"),
+		fmt.Sprintf("%*s", 7, "Level B"),
+		fmt.Sprintf("%*s", 6, "+/-"),
+		fmt.Sprintf("%*s", 11, "XP A"),
+		fmt.Sprintf("%*s", 11, "XP B"),
+		fmt.Sprintf("%*s", 11, "+/-"),
+		fmt.Sprintf("%*s", 9, "Rank A"),
+		fmt.Sprintf("%*s", 9, "Rank B"),
+		fmt.Sprintf("%*s", 9, "+/-"),
+	}}
+
+	for i, c := range compareSkills(a, b) {
+		name := "  " + coloredSkillName(c.ID)
+		if i == selectedMetric {
+			name = "> " + coloredSkillName(c.ID)
+		}
+
+		rank := fmt.Sprintf("%*s", 9, "-")
+		if d, ok := c.RankDelta(); ok {
+			rank = highlightDelta(d, 9)
+		}
+
+		table.Rows = append(table.Rows, []string{
+			name,
+			fmt.Sprintf("%*d", 7, skillLevel(c.A)),
+			fmt.Sprintf("%*d", 7, skillLevel(c.B)),
+			highlightDelta(c.LevelDelta(), 6),
+			fmt.Sprintf("%*d", 11, c.A.XP/10),
+			fmt.Sprintf("%*d", 11, c.B.XP/10),
+			highlightDelta(c.XPDelta(), 11),
+			fmt.Sprintf("%*s", 9, formatRank(c.A.Rank)),
+			fmt.Sprintf("%*s", 9, formatRank(c.B.Rank)),
+			rank,
+		})
+		table.RowStyles[i+1] = ui.Style{Fg: ui.ColorWhite}
+	}
+
+	ui.Render(table)
+}
+
+// runCompare prints the profiles of two players side by side
+func runCompare(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	var players [2]*runemetrics.PlayerInfo
+	for i, name := range args {
+		t, err := fetchOnce(name)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to fetch %q", name)
+		}
+		players[i] = t.Data
+	}
+
+	a, b := players[0], players[1]
+	for _, p := range []struct {
+		Label string
+		Info  *runemetrics.PlayerInfo
+	}{{"A", a}, {"B", b}} {
+		fmt.Printf("%s: %s: combat level %d, total level %d, total XP %d, rank %s\n",
+			p.Label, p.Info.Name, p.Info.CombatLevel, p.Info.TotalSkill, p.Info.TotalXP, formatRank(p.Info.NumericRank()))
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Skill\tLevel A\tLevel B\t+/-\tXP A\tXP B\t+/-\tRank A\tRank B\t+/-\t")
+
+	for _, c := range compareSkills(a, b) {
+		rank := "-"
+		if d, ok := c.RankDelta(); ok {
+			rank = formatDelta(d)
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			c.ID, skillLevel(c.A), skillLevel(c.B), formatDelta(c.LevelDelta()),
+			c.A.XP/10, c.B.XP/10, formatDelta(c.XPDelta()),
+			formatRank(c.A.Rank), formatRank(c.B.Rank), rank)
+	}
+
+	rank := "-"
+	if d, ok := rankDelta(a.NumericRank(), b.NumericRank()); ok {
+		rank = formatDelta(d)
+	}
+
+	fmt.Fprintf(w, "Total\t%d\t%d\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+		a.TotalSkill, b.TotalSkill, formatDelta(a.TotalSkill-b.TotalSkill),
+		a.TotalXP, b.TotalXP, formatDelta(a.TotalXP-b.TotalXP),
+		formatRank(a.NumericRank()), formatRank(b.NumericRank()), rank)
+
+	return errors.Wrap(w.Flush(), "Unable to write table")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type targetETA struct {
+	Known     bool
+	Confident bool
+	PlayTime  time.Duration
+	Date      time.Time
+}
+
+func (t targetETA) String() string {
+	if !t.Known {
+		return "-"
+	}
+
+	out := fmt.Sprintf("%.1fh %s", t.PlayTime.Hours(), t.Date.Format("01/02"))
+	if !t.Confident {
+		// Too little data to trust the estimate
+		out += "?"
+	}
+
+	return out
+}
+
+// updateRates recalculates the XP rates from the history within the
+// configured rate window
+func (t *trackedPlayer) updateRates() {
+	if t.History == nil {
+		return
+	}
+
+	r, err := t.History.Rates(time.Now().Add(-cfg.RateWindow), cfg.IdleTime)
+	if err != nil {
+		log.WithError(err).Error("Unable to calculate XP rates")
+		return
+	}
+
+	t.Rates = r
+}
+
+// estimateTarget calculates when the target level of the skill will be
+// reached based on the observed XP rates
+func (t *trackedPlayer) estimateTarget(s runemetrics.Skill, targetLevel int) targetETA {
+	var (
+		remaining    = float64(skillInfo(s.ID).XPToTargetLevel(targetLevel, s.XP/10) * 10)
+		playRate     = t.Rates.XPPerHour(s.ID)
+		calendarRate = t.Rates.XPPerCalendarHour(s.ID)
+	)
+
+	if playRate <= 0 || calendarRate <= 0 {
+		return targetETA{}
+	}
+
+	return targetETA{
+		Known:     true,
+		Confident: t.Rates.Samples[s.ID] >= cfg.RateMinSamples && t.Rates.PlayTime >= time.Hour,
+		PlayTime:  time.Duration(remaining / playRate * float64(time.Hour)),
+		Date:      time.Now().Add(time.Duration(remaining / calendarRate * float64(time.Hour))),
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+var (
+	descCombatLevel = prometheus.NewDesc("runemetrics_combat_level", "Combat level of the player", []string{"player"}, nil)
+	descLastSuccess = prometheus.NewDesc("runemetrics_last_success_timestamp_seconds", "Time of the last successful fetch of the profile", []string{"player"}, nil)
+	descLoggedIn    = prometheus.NewDesc("runemetrics_logged_in", "Whether the player is logged in (1) or not (0)", []string{"player"}, nil)
+	descQuests      = prometheus.NewDesc("runemetrics_quests", "Number of quests by state", []string{"player", "state"}, nil)
+	descRank        = prometheus.NewDesc("runemetrics_rank", "Overall rank of the player", []string{"player"}, nil)
+	descSkillLevel  = prometheus.NewDesc("runemetrics_skill_level", "Level of the skill", []string{"player", "skill"}, nil)
+	descSkillRank   = prometheus.NewDesc("runemetrics_skill_rank", "Rank of the player in the skill", []string{"player", "skill"}, nil)
+	descSkillXP     = prometheus.NewDesc("runemetrics_skill_xp", "XP of the player in the skill", []string{"player", "skill"}, nil)
+	descTotalLevel  = prometheus.NewDesc("runemetrics_total_level", "Total level of the player", []string{"player"}, nil)
+	descTotalXP     = prometheus.NewDesc("runemetrics_total_xp", "Total XP of the player", []string{"player"}, nil)
+	descUp          = prometheus.NewDesc("runemetrics_up", "Whether the last fetch of the profile succeeded (1) or not (0)", []string{"player"}, nil)
+)
+
+type exporterPlayer struct {
+	Info        *runemetrics.PlayerInfo
+	LastSuccess time.Time
+	Up          bool
+
+	// Tracked contains the goals of the player to complete them
+	Tracked *trackedPlayer
+}
+
+// exporter is a prometheus.Collector exposing the latest profiles of
+// the tracked players
+type exporter struct {
+	lock    sync.RWMutex
+	players map[string]*exporterPlayer
+}
+
+func newExporter(players []string) (*exporter, error) {
+	e := &exporter{players: map[string]*exporterPlayer{}}
+	for _, p := range players {
+		t, err := loadPlayer(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to load player %q", p)
+		}
+		e.players[p] = &exporterPlayer{Tracked: t}
+	}
+	return e, nil
+}
+
+// Describe implements prometheus.Collector
+func (e *exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		descCombatLevel, descLastSuccess, descLoggedIn, descQuests, descRank,
+		descSkillLevel, descSkillRank, descSkillXP, descTotalLevel, descTotalXP, descUp,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (e *exporter) Collect(ch chan<- prometheus.Metric) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	gauge := func(desc *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labels...)
+	}
+
+	for name, state := range e.players {
+		gauge(descUp, boolGauge(state.Up), name)
+
+		p := state.Info
+		if p == nil {
+			// Never fetched successfully, nothing else to expose
+			continue
+		}
+
+		gauge(descLastSuccess, float64(state.LastSuccess.Unix()), name)
+
+		gauge(descCombatLevel, float64(p.CombatLevel), name)
+		gauge(descLoggedIn, boolGauge(p.LoggedIn), name)
+		gauge(descQuests, float64(p.QuestsComplete), name, "complete")
+		gauge(descQuests, float64(p.QuestsNotStarted), name, "not_started")
+		gauge(descQuests, float64(p.QuestsStarted), name, "started")
+		gauge(descRank, float64(p.NumericRank()), name)
+		gauge(descTotalLevel, float64(p.TotalSkill), name)
+		gauge(descTotalXP, float64(p.TotalXP), name)
+
+		for _, s := range p.SkillValues {
+			skill := skillLabel(s.ID)
+			gauge(descSkillLevel, float64(s.Level), name, skill)
+			gauge(descSkillRank, float64(s.Rank), name, skill)
+			gauge(descSkillXP, float64(s.XP)/10, name, skill)
+		}
+	}
+}
+
+// update fetches the profiles of all tracked players, the activities
+// are fetched to detect new ones for the notifications
+func (e *exporter) update() {
+	for name, state := range e.players {
+		p, err := client.GetPlayerInfo(name, 20)
+
+		e.lock.Lock()
+		state.Up = err == nil
+		if err == nil {
+			p.MergeHistory(state.Info)
+			sendNotifications(detectNotifications(state.Info, p))
+			state.Info = p
+			state.LastSuccess = time.Now()
+		}
+		e.lock.Unlock()
+
+		if err != nil {
+			log.WithError(err).WithField("player", name).Error("Unable to fetch metrics")
+			continue
+		}
+
+		state.completeGoals(p)
+	}
+}
+
+// completeGoals marks the goals reached by the player as completed,
+// goals added through the goal command meanwhile are picked up first
+func (s *exporterPlayer) completeGoals(p *runemetrics.PlayerInfo) {
+	t := s.Tracked
+
+	if err := t.reloadGoals(); err != nil {
+		log.WithError(err).WithField("player", t.Name).Error("Unable to reload goals")
+	}
+
+	completed := t.completeGoals(p)
+	if len(completed) == 0 {
+		return
+	}
+
+	for _, g := range completed {
+		log.WithField("player", t.Name).WithField("goal", g.String()).Info("Goal completed")
+	}
+	sendNotifications(goalNotifications(p.Name, completed))
+
+	if err := t.saveGoals(); err != nil {
+		log.WithError(err).WithField("player", t.Name).Error("Unable to store goals")
+	}
+}
+
+// runServe exposes the profiles of the given players as Prometheus
+// metrics and updates them on the update schedule
+func runServe(args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cron := cronexpr.MustParse(cfg.Update)
+
+	e, err := newExporter(args)
+	if err != nil {
+		return err
+	}
+
+	if err := prometheus.Register(e); err != nil {
+		return errors.Wrap(err, "Unable to register exporter")
+	}
+
+	go func() {
+		for {
+			e.update()
+			time.Sleep(time.Until(cron.Next(time.Now())))
+		}
+	}()
+
+	http.Handle("/metrics", promhttp.Handler())
+
+	log.WithField("listen", cfg.Listen).Info("Serving metrics")
+	return errors.Wrap(http.ListenAndServe(cfg.Listen, nil), "Unable to serve metrics")
+}
+
+// skillLabel returns the skill name used as metric label, skills not
+// known yet are labelled by their ID to keep the label sets unique
+func skillLabel(id runemetrics.SkillID) string {
+	if name := id.String(); name != "" {
+		return name
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func boolGauge(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
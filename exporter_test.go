@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func TestExporterCollectUnknownSkills(t *testing.T) {
+	e := &exporter{players: map[string]*exporterPlayer{}}
+	e.players["Tester"] = &exporterPlayer{
+		Info: &runemetrics.PlayerInfo{
+			Name: "Tester",
+			SkillValues: []runemetrics.Skill{
+				{ID: 0, Level: 99, XP: 130344310},
+				{ID: 27, Level: 50, XP: 1012340},
+				{ID: 28, Level: 20, XP: 45000},
+			},
+		},
+		LastSuccess: time.Now(),
+		Up:          true,
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(e); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() = %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "runemetrics_skill_level" {
+			continue
+		}
+
+		var skills []string
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "skill" {
+					skills = append(skills, l.GetValue())
+				}
+			}
+		}
+
+		want := map[string]bool{"Attack": true, "27": true, "28": true}
+		if len(skills) != len(want) {
+			t.Fatalf("skill labels = %v, want %d labels", skills, len(want))
+		}
+		for _, s := range skills {
+			if !want[s] {
+				t.Errorf("unexpected skill label %q", s)
+			}
+		}
+		return
+	}
+
+	t.Fatal("runemetrics_skill_level not exposed")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+type fetchResult struct {
+	Target *trackedPlayer
+	Player *runemetrics.PlayerInfo
+	Err    error
+}
+
+// fetchWorker fetches the player info for every request received and
+// delivers the results without blocking the UI event loop. Requests are
+// processed one after another to not hammer the API when tracking
+// multiple players.
+func fetchWorker(requests <-chan *trackedPlayer, results chan<- fetchResult) {
+	for t := range requests {
+		pi, err := t.fetch()
+		results <- fetchResult{Target: t, Player: pi, Err: err}
+	}
+}
+
+// fetch fetches the player info and persists it into the cache and the
+// history
+func (t *trackedPlayer) fetch() (*runemetrics.PlayerInfo, error) {
+	pi, err := t.getPlayerInfo(20)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := storeCache(t.Name, pi); err != nil {
+		log.WithError(err).Error("Unable to write cache")
+	}
+
+	t.recordHistory(pi)
+	return pi, nil
+}
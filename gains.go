@@ -0,0 +1,112 @@
+package main
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/history"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type gainWindow int
+
+const (
+	gainWindowOff gainWindow = iota
+	gainWindowToday
+	gainWindowSession
+	gainWindowWeek
+	gainWindowCount // Keep last, used to cycle
+)
+
+var selectedGainWindow = gainWindowOff
+
+func (g gainWindow) Label() string {
+	switch g {
+	case gainWindowToday:
+		return "XP today"
+	case gainWindowSession:
+		return "XP session"
+	case gainWindowWeek:
+		return "XP 7 days"
+	default:
+		return ""
+	}
+}
+
+func (g gainWindow) Next() gainWindow {
+	return (g + 1) % gainWindowCount
+}
+
+// baseline returns the snapshot of the player to calculate the gains
+// against
+func (g gainWindow) baseline(t *trackedPlayer) (*history.Snapshot, error) {
+	switch g {
+
+	case gainWindowSession:
+		if s := t.Sessions.Current(time.Now()); s != nil {
+			return &s.Baseline, nil
+		}
+		return nil, nil
+
+	case gainWindowToday, gainWindowWeek:
+		if t.History == nil {
+			return nil, nil
+		}
+
+		now := time.Now()
+		since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		if g == gainWindowWeek {
+			since = now.Add(-7 * 24 * time.Hour)
+		}
+
+		return t.History.Baseline(since)
+
+	default:
+		return nil, nil
+
+	}
+}
+
+// updateGains recalculates the XP gained within the selected window
+// for every skill of the player and the total XP gained within all
+// windows
+func (t *trackedPlayer) updateGains() {
+	t.Gains = map[runemetrics.SkillID]int64{}
+	t.TotalGains = map[gainWindow]int64{}
+
+	p := t.Data
+	if p == nil {
+		return
+	}
+
+	for w := gainWindowToday; w < gainWindowCount; w++ {
+		base, err := w.baseline(t)
+		if err != nil {
+			log.WithError(err).Error("Unable to fetch baseline for XP gains")
+			continue
+		}
+
+		if base != nil {
+			t.TotalGains[w] = p.TotalXP - base.TotalXP
+		}
+	}
+
+	if selectedGainWindow == gainWindowOff {
+		return
+	}
+
+	base, err := selectedGainWindow.baseline(t)
+	if err != nil {
+		log.WithError(err).Error("Unable to fetch baseline for XP gains")
+		return
+	}
+
+	if base == nil {
+		return
+	}
+
+	for _, s := range p.SkillValues {
+		t.Gains[s.ID] = s.XP - base.Skill(s.ID).XP
+	}
+}
@@ -1,11 +1,13 @@
 module github.com/Luzifer/runemetrics
 
-go 1.12
+go 1.13
 
 require (
 	github.com/Luzifer/rconfig/v2 v2.2.1
 	github.com/gizak/termui/v3 v3.1.0
 	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75
 	github.com/pkg/errors v0.8.1
+	github.com/prometheus/client_golang v1.7.1
 	github.com/sirupsen/logrus v1.4.2
+	go.etcd.io/bbolt v1.3.5
 )
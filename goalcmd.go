@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+const goalUsage = `Usage:
+  runemetrics goal set <player> <skill> <level>
+  runemetrics goal add <player> <level|xp> <target> <skill,...> [by YYYY-MM-DD]
+  runemetrics goal add <player> <totallevel|totalxp> <target> [by YYYY-MM-DD]
+  runemetrics goal list <player>
+  runemetrics goal clear <player> [<id>]`
+
+// errGoalUsage signals the goal command was called with invalid arguments
+var errGoalUsage = errors.New("Invalid goal command")
+
+// runGoalCommand manages the goals of a player from the command line
+// based on the cached profile without contacting the API
+func runGoalCommand(args []string) error {
+	if len(args) < 2 {
+		return errGoalUsage
+	}
+
+	cmd := args[0]
+
+	t, err := loadPlayer(args[1])
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+
+	case "add":
+		if len(args) < 4 {
+			return errGoalUsage
+		}
+
+		g, err := parseGoal(strings.Join(args[2:], " "), t.Cache)
+		if err != nil {
+			return err
+		}
+
+		g = t.addGoal(g)
+		fmt.Printf("Added goal %d: %s\n", g.ID, g)
+
+	case "clear":
+		if len(args) > 3 {
+			return errGoalUsage
+		}
+
+		filter := func(g goal) bool { return g.Completed.IsZero() }
+		if len(args) == 3 {
+			id, err := strconv.Atoi(args[2])
+			if err != nil {
+				return errors.Wrap(err, "Unable to parse goal ID")
+			}
+			filter = func(g goal) bool { return g.ID == id }
+		}
+
+		fmt.Printf("Removed %d goal(s)\n", t.removeGoals(filter))
+
+	case "list":
+		return t.listGoals()
+
+	case "set":
+		if len(args) != 4 {
+			return errGoalUsage
+		}
+
+		id, err := parseSkill(args[2])
+		if err != nil {
+			return err
+		}
+
+		sk := runemetrics.Skill{ID: id}
+		if t.Cache != nil {
+			sk = t.Cache.GetSkill(id)
+			sk.ID = id
+		}
+
+		if err = t.setSkillTarget(t.Cache, sk, args[3]); err != nil {
+			return err
+		}
+
+	default:
+		return errGoalUsage
+
+	}
+
+	return errors.Wrap(t.saveGoals(), "Unable to store goals")
+}
+
+// listGoals prints the goals of the player, progress is only known if
+// there is a cached profile
+func (t *trackedPlayer) listGoals() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tGoal\tProgress\tRemaining\tDeadline\tCompleted")
+
+	for _, g := range t.Goals {
+		var (
+			deadline  = "-"
+			completed = "-"
+			progress  = "-"
+			remaining = "-"
+		)
+
+		if !g.Deadline.IsZero() {
+			deadline = g.Deadline.Local().Format(goalDeadlineFormat)
+		}
+
+		if !g.Completed.IsZero() {
+			completed = g.Completed.Local().Format("2006-01-02 15:04")
+		}
+
+		if t.Cache != nil {
+			pr := g.progress(t.Cache)
+			progress = strconv.FormatFloat(pr.Percentage(), 'f', 1, 64) + "%"
+			remaining = fmt.Sprintf("%d %s", pr.Remaining(), pr.Unit)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", g.ID, g, progress, remaining, deadline, completed)
+	}
+
+	return errors.Wrap(w.Flush(), "Unable to write goal list")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+const goalsVersion = 1
+
+type goalsDocument struct {
+	Version int    `json:"version"`
+	Goals   []goal `json:"goals"`
+}
+
+func getConfigDir() (string, error) {
+	if cfg.ConfigDir != "" {
+		return cfg.ConfigDir, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to retrieve user config dir")
+	}
+
+	return path.Join(configDir, "luzifer", "runemetrics"), nil
+}
+
+func getGoalsFile(player string) (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(configDir, runemetrics.NormalizePlayerName(player)+".goals.json"), nil
+}
+
+// loadGoals reads the goals of the player from the goals file, goals
+// stored in the cache by earlier versions are moved into a new one
+func (t *trackedPlayer) loadGoals(cached []goal) error {
+	goalsFile, err := getGoalsFile(t.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(goalsFile); err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "Unable to stat goals file")
+		}
+
+		if len(cached) > 0 {
+			log.WithField("player", t.Name).Info("Moving goals from cache into goals file")
+			t.Goals = cached
+			return t.saveGoals()
+		}
+
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(goalsFile)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read goals file")
+	}
+
+	var doc goalsDocument
+	if err = json.Unmarshal(raw, &doc); err != nil {
+		return errors.Wrap(err, "Unable to decode goals file")
+	}
+
+	if doc.Version > goalsVersion {
+		return errors.Errorf("Goals file was written by a newer version (%d > %d)", doc.Version, goalsVersion)
+	}
+
+	dataLock.Lock()
+	t.Goals = doc.Goals
+	dataLock.Unlock()
+
+	t.GoalsModTime, err = fileModTime(goalsFile)
+	return err
+}
+
+// saveGoals persists the current goals of the player
+func (t *trackedPlayer) saveGoals() error {
+	goalsFile, err := getGoalsFile(t.Name)
+	if err != nil {
+		return err
+	}
+
+	dataLock.RLock()
+	err = writeFileAtomic(goalsFile, goalsDocument{Version: goalsVersion, Goals: t.Goals})
+	dataLock.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	t.GoalsModTime, err = fileModTime(goalsFile)
+	return err
+}
+
+// reloadGoals reads the goals file again if it was changed by someone
+// else since it was last read or written
+func (t *trackedPlayer) reloadGoals() error {
+	goalsFile, err := getGoalsFile(t.Name)
+	if err != nil {
+		return err
+	}
+
+	modTime, err := fileModTime(goalsFile)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+
+	if modTime.Equal(t.GoalsModTime) {
+		return nil
+	}
+
+	return t.loadGoals(nil)
+}
+
+func fileModTime(file string) (time.Time, error) {
+	stat, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "Unable to stat file")
+	}
+
+	return stat.ModTime(), nil
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type goalKind string
+
+const (
+	goalLevel      goalKind = "level"       // Target level in every skill of the goal
+	goalXP         goalKind = "xp"          // Target XP in every skill of the goal
+	goalTotalLevel goalKind = "total_level" // Target total level
+	goalTotalXP    goalKind = "total_xp"    // Target total XP
+)
+
+const goalDeadlineFormat = "2006-01-02"
+
+// goal describes a target the player wants to reach, optionally until
+// a deadline
+type goal struct {
+	ID       int                      `json:"id"`
+	Kind     goalKind                 `json:"kind"`
+	Skills   []runemetrics.SkillID    `json:"skills,omitempty"`
+	Target   int64                    `json:"target"` // Level or XP depending on the kind
+	Deadline time.Time                `json:"deadline"`
+	Mode     runemetrics.ProgressMode `json:"mode,omitempty"`
+
+	Created   time.Time `json:"created"`
+	Completed time.Time `json:"completed"`
+
+	// StartXP contains the XP (in tenths) of the skills and StartTotal
+	// the total level or XP when the goal was created
+	StartXP    map[runemetrics.SkillID]int64 `json:"start_xp,omitempty"`
+	StartTotal int64                         `json:"start_total,omitempty"`
+}
+
+// goalProgress describes how far the player got towards a goal in the
+// unit of the goal (XP or levels)
+type goalProgress struct {
+	Base    int64
+	Current int64
+	Target  int64
+	Unit    string
+}
+
+// newGoal creates a goal starting from the current state of the player
+func newGoal(kind goalKind, skills []runemetrics.SkillID, target int64, deadline time.Time, p *runemetrics.PlayerInfo) goal {
+	g := goal{
+		Kind:     kind,
+		Skills:   skills,
+		Target:   target,
+		Deadline: deadline,
+		Mode:     runemetrics.ProgressMode(cfg.TargetProgress),
+		Created:  time.Now(),
+		StartXP:  map[runemetrics.SkillID]int64{},
+	}
+
+	if p == nil {
+		return g
+	}
+
+	for _, id := range skills {
+		g.StartXP[id] = p.GetSkill(id).XP
+	}
+
+	switch kind {
+	case goalTotalLevel:
+		g.StartTotal = p.TotalSkill
+	case goalTotalXP:
+		g.StartTotal = p.TotalXP
+	}
+
+	return g
+}
+
+// parseGoal creates a goal from a textual description:
+//
+//	<level|xp> <target> <skill>[,<skill>...] [by YYYY-MM-DD]
+//	<totallevel|totalxp> <target> [by YYYY-MM-DD]
+func parseGoal(spec string, p *runemetrics.PlayerInfo) (goal, error) {
+	var (
+		deadline time.Time
+		err      error
+		fields   = strings.Fields(strings.ToLower(spec))
+	)
+
+	if n := len(fields); n >= 2 && fields[n-2] == "by" {
+		if deadline, err = time.ParseInLocation(goalDeadlineFormat, fields[n-1], time.Local); err != nil {
+			return goal{}, errors.Wrap(err, "Unable to parse deadline")
+		}
+		// Goal should be reached by the end of the given day
+		deadline = deadline.AddDate(0, 0, 1).Add(-time.Second)
+		fields = fields[:n-2]
+	}
+
+	if len(fields) < 2 {
+		return goal{}, errors.New("Goal needs a kind and a target")
+	}
+
+	target, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil || target <= 0 {
+		return goal{}, errors.Errorf("Invalid target %q", fields[1])
+	}
+
+	switch fields[0] {
+
+	case "level", "xp":
+		if len(fields) != 3 {
+			return goal{}, errors.New("Goal needs a comma separated list of skills")
+		}
+
+		var skills []runemetrics.SkillID
+		for _, name := range strings.Split(fields[2], ",") {
+			id, err := parseSkill(name)
+			if err != nil {
+				return goal{}, err
+			}
+
+			if levelCap := skillInfo(id).Cap(); fields[0] == "level" && target > int64(levelCap) {
+				return goal{}, errors.Errorf("Level %d is above the level cap of %s (%d)", target, id, levelCap)
+			}
+
+			if fields[0] == "xp" && target > runemetrics.MaxXP {
+				return goal{}, errors.Errorf("XP %d is above the XP limit of %d", target, runemetrics.MaxXP)
+			}
+
+			skills = append(skills, id)
+		}
+
+		return newGoal(goalKind(fields[0]), skills, target, deadline, p), nil
+
+	case "totallevel", "totalxp":
+		if len(fields) != 2 {
+			return goal{}, errors.New("Total goals do not take skills")
+		}
+
+		kind := goalTotalLevel
+		if fields[0] == "totalxp" {
+			kind = goalTotalXP
+		}
+
+		return newGoal(kind, nil, target, deadline, p), nil
+
+	default:
+		return goal{}, errors.Errorf("Unknown goal kind %q", fields[0])
+
+	}
+}
+
+// parseSkill finds the skill by its name or an unambiguous prefix of it
+func parseSkill(name string) (runemetrics.SkillID, error) {
+	var matches []runemetrics.SkillInfo
+
+	for _, s := range runemetrics.SkillList {
+		switch {
+		case strings.EqualFold(s.Name, name):
+			return runemetrics.SkillID(s.ID), nil
+		case name != "" && strings.HasPrefix(strings.ToLower(s.Name), strings.ToLower(name)):
+			matches = append(matches, s)
+		}
+	}
+
+	if len(matches) != 1 {
+		return 0, errors.Errorf("Unknown skill %q", name)
+	}
+
+	return runemetrics.SkillID(matches[0].ID), nil
+}
+
+// String returns a human readable description of the goal
+func (g goal) String() string {
+	var skills []string
+	for _, id := range g.Skills {
+		skills = append(skills, id.String())
+	}
+
+	switch g.Kind {
+	case goalLevel:
+		return fmt.Sprintf("Level %d %s", g.Target, strings.Join(skills, ", "))
+	case goalXP:
+		return fmt.Sprintf("%s XP %s", shortNumber(g.Target), strings.Join(skills, ", "))
+	case goalTotalLevel:
+		return fmt.Sprintf("Total level %d", g.Target)
+	case goalTotalXP:
+		return fmt.Sprintf("%s total XP", shortNumber(g.Target))
+	default:
+		return string(g.Kind)
+	}
+}
+
+// hasSkill tells whether the skill is part of the goal
+func (g goal) hasSkill(id runemetrics.SkillID) bool {
+	for _, s := range g.Skills {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
+
+// skillTargetXP returns the XP the skill needs to reach for the goal,
+// virtual target levels count even when virtual levels are not shown
+func (g goal) skillTargetXP(info runemetrics.SkillInfo) int64 {
+	if g.Kind == goalXP {
+		return g.Target
+	}
+	return info.LevelXP(int(g.Target))
+}
+
+// skillBaseXP returns the XP the progress of the skill towards the goal
+// is measured from
+func (g goal) skillBaseXP(s runemetrics.Skill, info runemetrics.SkillInfo) int64 {
+	switch g.Mode {
+	case runemetrics.ProgressFromStart:
+		return g.StartXP[s.ID] / 10
+	case runemetrics.ProgressFromLevel:
+		return info.LevelXP(info.LevelFromXP(s.XP / 10))
+	default:
+		return 0
+	}
+}
+
+// progress calculates the progress of the player towards the goal
+func (g goal) progress(p *runemetrics.PlayerInfo) goalProgress {
+	switch g.Kind {
+
+	case goalTotalLevel, goalTotalXP:
+		out := goalProgress{Target: g.Target, Current: p.TotalXP, Unit: "XP"}
+		if g.Kind == goalTotalLevel {
+			out.Current, out.Unit = p.TotalSkill, "levels"
+		}
+		if g.Mode == runemetrics.ProgressFromStart {
+			out.Base = g.StartTotal
+		}
+		return out
+
+	default:
+		// Skills exceeding the target must not make up for the others
+		out := goalProgress{Unit: "XP"}
+		for _, id := range g.Skills {
+			var (
+				info     = skillInfo(id)
+				s        = p.GetSkill(id)
+				targetXP = g.skillTargetXP(info)
+			)
+
+			out.Target += targetXP
+			out.Current += minInt64(s.XP/10, targetXP)
+			out.Base += minInt64(g.skillBaseXP(s, info), targetXP)
+		}
+		return out
+
+	}
+}
+
+// Done tells whether the goal is reached
+func (g goalProgress) Done() bool { return g.Current >= g.Target }
+
+// Percentage returns the progress measured from the base
+func (g goalProgress) Percentage() float64 {
+	if g.Done() || g.Target <= g.Base {
+		return 100
+	}
+	return math.Max(0, float64(g.Current-g.Base)/float64(g.Target-g.Base)*100)
+}
+
+// Remaining returns the amount still missing to reach the goal
+func (g goalProgress) Remaining() int64 {
+	if g.Done() {
+		return 0
+	}
+	return g.Target - g.Current
+}
+
+// Daily returns the amount required per day to reach the goal by the
+// deadline, the bool is false if the goal has no deadline or is overdue
+func (g goalProgress) Daily(deadline time.Time) (int64, bool) {
+	if deadline.IsZero() {
+		return 0, false
+	}
+
+	days := math.Ceil(time.Until(deadline).Hours() / 24)
+	if days <= 0 {
+		return 0, false
+	}
+
+	return int64(math.Ceil(float64(g.Remaining()) / days)), true
+}
+
+// addGoal stores the goal assigning it the next free ID
+func (t *trackedPlayer) addGoal(g goal) goal {
+	dataLock.Lock()
+	defer dataLock.Unlock()
+
+	for _, o := range t.Goals {
+		if o.ID >= g.ID {
+			g.ID = o.ID + 1
+		}
+	}
+	if g.ID == 0 {
+		g.ID = 1
+	}
+
+	t.Goals = append(t.Goals, g)
+	return g
+}
+
+// removeGoals removes all goals matching the filter
+func (t *trackedPlayer) removeGoals(filter func(goal) bool) int {
+	dataLock.Lock()
+	defer dataLock.Unlock()
+
+	var (
+		kept    []goal
+		removed int
+	)
+
+	for _, g := range t.Goals {
+		if filter(g) {
+			removed++
+			continue
+		}
+		kept = append(kept, g)
+	}
+
+	t.Goals = kept
+	return removed
+}
+
+// skillGoal returns the nearest open level goal for the skill
+func (t *trackedPlayer) skillGoal(s runemetrics.Skill) (goal, bool) {
+	var (
+		found bool
+		out   goal
+		level = skillInfo(s.ID).LevelFromXP(s.XP / 10)
+	)
+
+	for _, g := range t.Goals {
+		if g.Kind != goalLevel || !g.Completed.IsZero() || !g.hasSkill(s.ID) || int(g.Target) <= level {
+			continue
+		}
+
+		if !found || g.Target < out.Target {
+			found, out = true, g
+		}
+	}
+
+	return out, found
+}
+
+// setSkillTarget replaces the single skill level goal of the skill with
+// one for the given level, a level not above the current one removes it
+func (t *trackedPlayer) setSkillTarget(p *runemetrics.PlayerInfo, s runemetrics.Skill, input string) error {
+	var tlvl int
+
+	if input != "" {
+		var err error
+		if tlvl, err = strconv.Atoi(input); err != nil {
+			return errors.Wrap(err, "Unable to parse target level")
+		}
+	}
+
+	info := skillInfo(s.ID)
+	if tlvl > info.Cap() {
+		return errors.Errorf("Level %d is above the level cap of %s (%d)", tlvl, s.ID, info.Cap())
+	}
+
+	t.removeGoals(func(g goal) bool {
+		return g.Kind == goalLevel && g.Completed.IsZero() && len(g.Skills) == 1 && g.Skills[0] == s.ID
+	})
+
+	if tlvl <= info.LevelFromXP(s.XP/10) {
+		return nil
+	}
+
+	t.addGoal(newGoal(goalLevel, []runemetrics.SkillID{s.ID}, int64(tlvl), time.Time{}, p))
+	return nil
+}
+
+// completeGoals marks the open goals reached by the player as completed
+// and returns them
+func (t *trackedPlayer) completeGoals(p *runemetrics.PlayerInfo) []goal {
+	dataLock.Lock()
+	defer dataLock.Unlock()
+
+	var completed []goal
+	for i, g := range t.Goals {
+		if !g.Completed.IsZero() || !g.progress(p).Done() {
+			continue
+		}
+
+		t.Goals[i].Completed = time.Now()
+		completed = append(completed, t.Goals[i])
+	}
+
+	return completed
+}
+
+// cycleGoalMode switches the progress mode of the goal with the given ID
+func (t *trackedPlayer) cycleGoalMode(id int) {
+	dataLock.Lock()
+	defer dataLock.Unlock()
+
+	for i := range t.Goals {
+		if t.Goals[i].ID == id {
+			t.Goals[i].Mode = t.Goals[i].Mode.Next()
+		}
+	}
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func TestParseGoal(t *testing.T) {
+	for _, tc := range []struct {
+		Spec    string
+		Want    string
+		WantErr bool
+	}{
+		{Spec: "level 99 attack", Want: "Level 99 Attack"},
+		{Spec: "level 120 invention", Want: "Level 120 Invention"},
+		{Spec: "level 150 attack", WantErr: true},
+		{Spec: "level 120 attack,invention", WantErr: true},
+		{Spec: "level 0 attack", WantErr: true},
+		{Spec: "level 99 foo", WantErr: true},
+		{Spec: "xp 150000000 attack", Want: "150M XP Attack"},
+		{Spec: "xp 200000000 attack", Want: "200M XP Attack"},
+		{Spec: "xp 200000001 attack", WantErr: true},
+		{Spec: "totallevel 2000", Want: "Total level 2000"},
+		{Spec: "totallevel 2000 attack", WantErr: true},
+		{Spec: "level 99 attack by 2026-13-01", WantErr: true},
+	} {
+		t.Run(tc.Spec, func(t *testing.T) {
+			g, err := parseGoal(tc.Spec, nil)
+			if tc.WantErr {
+				if err == nil {
+					t.Errorf("parseGoal(%q) = %q, want error", tc.Spec, g)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseGoal(%q) failed: %s", tc.Spec, err)
+			}
+			if g.String() != tc.Want {
+				t.Errorf("parseGoal(%q) = %q, want %q", tc.Spec, g, tc.Want)
+			}
+		})
+	}
+}
+
+func TestSkillGoalVirtualTarget(t *testing.T) {
+	defer func(v bool) { cfg.VirtualLevels = v }(cfg.VirtualLevels)
+
+	var (
+		info = runemetrics.SkillID(0).Info()
+		g    = goal{Kind: goalLevel, Skills: []runemetrics.SkillID{0}, Target: 110}
+		p    = &runemetrics.PlayerInfo{SkillValues: []runemetrics.Skill{
+			{ID: 0, Level: 99, XP: info.LevelXP(100) * 10},
+		}}
+	)
+
+	// Reaching the level cap must not complete a virtual target level
+	// when virtual levels are not shown
+	cfg.VirtualLevels = false
+	if g.progress(p).Done() {
+		t.Error("level 110 goal done at level 100")
+	}
+
+	p.SkillValues[0].XP = info.LevelXP(110) * 10
+	if !g.progress(p).Done() {
+		t.Error("level 110 goal not done at level 110")
+	}
+}
+
+func TestSetSkillTarget(t *testing.T) {
+	defer func(v bool) { cfg.VirtualLevels = v }(cfg.VirtualLevels)
+
+	for _, tc := range []struct {
+		Virtual bool
+		Input   string
+		Want    int64 // 0: no goal
+		WantErr bool
+	}{
+		{Input: "99", Want: 99},
+		{Input: "", Want: 0},
+		{Input: "1", Want: 0},
+		{Input: "100", WantErr: true},
+		{Input: "foo", WantErr: true},
+		{Virtual: true, Input: "120", Want: 120},
+		{Virtual: true, Input: "127", WantErr: true},
+	} {
+		t.Run(tc.Input, func(t *testing.T) {
+			cfg.VirtualLevels = tc.Virtual
+
+			var (
+				s  = runemetrics.Skill{ID: 0, Level: 50, XP: 1012340}
+				tp = &trackedPlayer{Goals: []goal{{ID: 1, Kind: goalLevel, Skills: []runemetrics.SkillID{0}, Target: 60}}}
+			)
+
+			err := tp.setSkillTarget(nil, s, tc.Input)
+			if tc.WantErr {
+				if err == nil {
+					t.Fatalf("setSkillTarget(%q) succeeded, want error", tc.Input)
+				}
+				if len(tp.Goals) != 1 || tp.Goals[0].Target != 60 {
+					t.Errorf("goals = %+v, want previous goal kept", tp.Goals)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("setSkillTarget(%q) failed: %s", tc.Input, err)
+			}
+
+			switch {
+			case tc.Want == 0 && len(tp.Goals) != 0:
+				t.Errorf("goals = %+v, want none", tp.Goals)
+			case tc.Want != 0 && (len(tp.Goals) != 1 || tp.Goals[0].Target != tc.Want):
+				t.Errorf("goals = %+v, want single goal for level %d", tp.Goals, tc.Want)
+			}
+		})
+	}
+}
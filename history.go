@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/history"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func openHistory(player string) (*history.Store, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Unable to create cache dir")
+	}
+
+	return history.Open(path.Join(cacheDir, runemetrics.NormalizePlayerName(player)+".history.db"))
+}
+
+func (t *trackedPlayer) recordHistory(p *runemetrics.PlayerInfo) {
+	if t.History == nil {
+		return
+	}
+
+	stored, err := t.History.Record(history.NewSnapshot(p, time.Now()))
+	if err != nil {
+		log.WithError(err).Error("Unable to record history")
+		return
+	}
+
+	log.WithField("stored", stored).Debug("Recorded history snapshot")
+}
+
+// restoreSessions replays the recent history into the session tracker
+// to pick up a session started before the program was launched
+func (t *trackedPlayer) restoreSessions() error {
+	t.Sessions = history.NewSessionTracker(cfg.IdleTime)
+
+	if t.History == nil {
+		return nil
+	}
+
+	snaps, err := t.History.Snapshots(time.Now().Add(-24*time.Hour), time.Now())
+	if err != nil {
+		return errors.Wrap(err, "Unable to load snapshots")
+	}
+
+	for _, snap := range snaps {
+		t.Sessions.Observe(snap)
+	}
+
+	return nil
+}
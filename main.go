@@ -2,18 +2,27 @@ package main
 
 import (
 	"fmt"
+	"image"
 	"math"
+	"math/rand"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 	"github.com/gorhill/cronexpr"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/Luzifer/rconfig/v2"
+
+	"github.com/Luzifer/runemetrics/pkg/history"
+	"github.com/Luzifer/runemetrics/pkg/notify"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
 )
 
 const (
@@ -24,189 +33,560 @@ const (
 
 var (
 	cfg = struct {
+		APIBase        string        `flag:"api-base" default:"https://apps.runescape.com/runemetrics" description:"Base URL of the RuneMetrics API"`
+		CacheDir       string        `flag:"cache-dir" default:"" description:"Directory to store the player caches in (default: user cache dir)"`
+		ConfigDir      string        `flag:"config-dir" default:"" description:"Directory to store the player goals in (default: user config dir)"`
+		IdleTime       time.Duration `flag:"idle-time" default:"15m" description:"Time without XP changes after which the player is considered idle (ends play sessions, excluded from XP rates), must be longer than the update interval"`
+		Listen         string        `flag:"listen" default:":9187" description:"Address to expose the metrics on (serve command)"`
+		Notify         []string      `flag:"notify" default:"" description:"Webhook to send notifications to (webhook:<url>, discord:<url> or slack:<url>), can be repeated"`
+		NotifyCommand  string        `flag:"notify-command" default:"" description:"Shell command to execute for every notification"`
+		NotifyKinds    []string      `flag:"notify-kinds" default:"activity,goal_completed,level_up,rank" description:"Kinds of notifications to send"`
+		NotifyRanks    []int         `flag:"notify-ranks" default:"100,1000,10000,100000" description:"Notify when the overall or a skill rank reaches one of these ranks"`
 		MarkerTime     time.Duration `flag:"marker-time" default:"30m" description:"How long to highlight new entries"`
+		SkillColors    string        `flag:"skill-colors" default:"auto" description:"Render skills in their colors (auto, always, never), requires a 256 color terminal"`
+		TargetProgress string        `flag:"target-progress" default:"start" description:"How to measure the progress of new targets (absolute, start, level)"`
+		VirtualLevels  bool          `flag:"virtual-levels" default:"false" description:"Display virtual levels above the level cap"`
+		Roster         string        `flag:"roster" default:"" description:"File with player names (one per line) to track in addition to the given ones"`
+		RateMinSamples int           `flag:"rate-min-samples" default:"5" description:"Number of XP changes required to consider an ETA reliable"`
+		RateWindow     time.Duration `flag:"rate-window" default:"168h" description:"Window of history to calculate the XP rates for ETAs from"`
+		RequestTimeout time.Duration `flag:"request-timeout" default:"15s" description:"Timeout for a single request to the RuneMetrics API"`
+		RetryBaseDelay time.Duration `flag:"retry-base-delay" default:"5s" description:"Delay before the first retry of a failed fetch, doubled on every retry"`
+		RetryMax       int           `flag:"retry-max" default:"5" description:"How often to retry a failed fetch before waiting for the next update (0 = disabled)"`
+		RetryMaxDelay  time.Duration `flag:"retry-max-delay" default:"2m" description:"Maximum delay between two retries"`
 		Update         string        `flag:"update" default:"* * * * *" description:"When to fetch metrics (cron syntax)"`
 		LogLevel       string        `flag:"log-level" default:"info" description:"Log level (debug, info, warn, error, fatal)"`
 		VersionAndExit bool          `flag:"version" default:"false" description:"Prints current version and exits"`
 	}{}
 
 	eventsPage     = 0
-	lastUpdate     = map[string]time.Time{}
-	playerData     *playerInfo
 	selectedMetric = 0
+	spinnerFrame   = 0
 
+	inputAction func(string) error
 	inputPrompt string
 	inputBuffer string
 
 	version = "dev"
 )
 
-func init() {
+// initApp parses the commandline options and sets up the client and
+// the notification sinks
+func initApp() {
 	if err := rconfig.ParseAndValidate(&cfg); err != nil {
 		log.Fatalf("Unable to parse commandline options: %s", err)
 	}
 
 	if cfg.VersionAndExit {
-		fmt.Printf("git-changerelease %s\n", version)
+		printVersion()
 		os.Exit(0)
 	}
 
+	if !runemetrics.ProgressMode(cfg.TargetProgress).IsValid() {
+		log.Fatalf("Invalid target progress mode %q", cfg.TargetProgress)
+	}
+
 	if l, err := log.ParseLevel(cfg.LogLevel); err != nil {
 		log.WithError(err).Fatal("Unable to parse log level")
 	} else {
 		log.SetLevel(l)
 	}
+
+	cron, err := cronexpr.Parse(cfg.Update)
+	if err != nil {
+		log.WithError(err).Fatal("Unable to parse update schedule")
+	}
+
+	// Without an XP change between two updates the player would be
+	// considered idle while playing
+	if interval := updateInterval(cron, time.Now()); cfg.IdleTime <= interval {
+		log.Fatalf("Idle time (%s) must be longer than the update interval (%s)", cfg.IdleTime, interval)
+	}
+
+	client = runemetrics.New(
+		runemetrics.WithBaseURL(cfg.APIBase),
+		runemetrics.WithHTTPClient(&http.Client{Timeout: cfg.RequestTimeout}),
+	)
+
+	for _, spec := range cfg.Notify {
+		sink, err := notify.ParseSink(spec, &http.Client{Timeout: cfg.RequestTimeout})
+		if err != nil {
+			log.WithError(err).Fatal("Unable to configure notifications")
+		}
+		notifySinks = append(notifySinks, sink)
+	}
+
+	if cfg.NotifyCommand != "" {
+		notifySinks = append(notifySinks, notify.Command{Command: cfg.NotifyCommand})
+	}
+
+	rand.Seed(time.Now().UnixNano())
+}
+
+// updateInterval returns the longest time between two of the next
+// updates of the schedule
+func updateInterval(cron *cronexpr.Expression, from time.Time) time.Duration {
+	var (
+		interval time.Duration
+		next     = cron.NextN(from, 10)
+	)
+
+	for i := 1; i < len(next); i++ {
+		if d := next[i].Sub(next[i-1]); d > interval {
+			interval = d
+		}
+	}
+
+	return interval
 }
 
 func main() {
-	var err error
+	initApp()
+
+	args := rconfig.Args()[1:]
+	if len(args) == 0 && cfg.Roster == "" {
+		usage()
+		os.Exit(1)
+	}
+
+	var (
+		cmd command
+		ok  bool
+	)
+	if len(args) > 0 {
+		cmd, ok = commands[args[0]]
+	}
+	if !ok {
+		// Calling with only player names (or a roster) starts the TUI
+		cmd, args = commands["tui"], append([]string{"tui"}, args...)
+	}
+
+	switch err := cmd.Run(args[1:]); {
+	case err == errUsage:
+		fmt.Fprintf(os.Stderr, "Usage: runemetrics %s %s\n", args[0], cmd.Args)
+		os.Exit(1)
+	case err == errGoalUsage:
+		fmt.Fprintln(os.Stderr, goalUsage)
+		os.Exit(1)
+	case err != nil:
+		log.WithError(err).Fatalf("Unable to execute %s command", args[0])
+	}
+}
+
+// runTUI starts the interactive terminal UI for the given players and
+// the players listed in the roster file
+func runTUI(args []string) error {
+	names := args
+	if cfg.Roster != "" {
+		roster, err := readRoster(cfg.Roster)
+		if err != nil {
+			return err
+		}
+		names = append(names, roster...)
+	}
 
-	if len(rconfig.Args()) != 2 {
-		log.Fatal("Usage: runemetrics <player>")
+	if len(names) == 0 {
+		return errUsage
 	}
 
-	if playerInfoCache, err = loadPlayerInfoCache(); err != nil {
-		log.WithError(err).Fatal("Unable to load cache")
+	for _, name := range names {
+		t, err := loadPlayer(name)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to load player %q", name)
+		}
+
+		t.openHistory()
+		defer t.Close()
+
+		if err := t.restoreSessions(); err != nil {
+			log.WithError(err).Warn("Unable to restore sessions from history")
+		}
+
+		trackedPlayers = append(trackedPlayers, t)
 	}
+	activePlayer = trackedPlayers[0]
 
-	if err = ui.Init(); err != nil {
-		log.WithError(err).Fatal("Unable to initialize termui")
+	if err := ui.Init(); err != nil {
+		return errors.Wrap(err, "Unable to initialize termui")
 	}
 	defer ui.Close()
 
+	initSkillColors()
+
 	var (
-		cron         = cronexpr.MustParse(cfg.Update)
-		player       = rconfig.Args()[1]
-		updateTicker = time.NewTimer(0)
+		cron          = cronexpr.MustParse(cfg.Update)
+		fetchRequests = make(chan *trackedPlayer, len(trackedPlayers))
+		fetchResults  = make(chan fetchResult, len(trackedPlayers))
+		spinnerTicker = time.NewTicker(100 * time.Millisecond)
+		uiEvents      = ui.PollEvents()
+		updateTicker  = time.NewTimer(0)
 	)
+	defer spinnerTicker.Stop()
+
+	go fetchWorker(fetchRequests, fetchResults)
 
 	for {
 		select {
 
-		case evt := <-ui.PollEvents():
+		case evt := <-uiEvents:
+			if inputPrompt != "" && handleInput(evt) {
+				updateUI(activePlayer, nil)
+				continue
+			}
+
+			// Keys apply to the player active when they were pressed
+			var (
+				active     = activePlayer
+				playerData = active.Data
+			)
+
 			switch evt.ID {
 
-			case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
+			case "q", "<C-c>":
+				return nil
+
+			case "t":
+				if inputPrompt != "" || playerData == nil {
+					continue
+				}
+				sk := playerData.SkillValues[selectedMetric]
+				prompt("Enter target level", func(input string) error {
+					return active.setSkillTarget(playerData, sk, input)
+				})
+				updateUI(active, nil)
+
+			case "d":
 				if inputPrompt != "" {
-					inputBuffer = inputBuffer + evt.ID
+					continue
 				}
-				updateUI(playerData, nil)
+				prompt("Delete goal (ID)", func(input string) error {
+					id, err := strconv.Atoi(input)
+					if err != nil {
+						return errors.Wrap(err, "Unable to parse goal ID")
+					}
+					if active.removeGoals(func(g goal) bool { return g.ID == id }) == 0 {
+						return errors.Errorf("There is no goal with ID %d", id)
+					}
+					return nil
+				})
+				updateUI(active, nil)
 
-			case "q", "<C-c>":
-				return
+			case "g":
+				if inputPrompt != "" {
+					continue
+				}
+				togglePanel(panelGoals)
+				updateUI(active, nil)
 
-			case "t":
+			case "n":
+				if inputPrompt != "" {
+					continue
+				}
+				prompt("New goal: <level|xp> <target> <skill,...> / <totallevel|totalxp> <target> [by YYYY-MM-DD]", func(input string) error {
+					g, err := parseGoal(input, playerData)
+					if err != nil {
+						return err
+					}
+					active.addGoal(g)
+					return nil
+				})
+				updateUI(active, nil)
+
+			case "c":
+				if inputPrompt != "" {
+					continue
+				}
+				togglePanel(panelCharts)
+				updateUI(active, nil)
+
+			case "p":
+				if inputPrompt != "" || playerData == nil {
+					continue
+				}
+				g, ok := active.skillGoal(playerData.SkillValues[selectedMetric])
+				if !ok {
+					continue
+				}
+				active.cycleGoalMode(g.ID)
+				if err := active.saveGoals(); err != nil {
+					log.WithError(err).Error("Unable to store goals")
+				}
+				updateUI(active, nil)
+
+			case "r":
+				if inputPrompt != "" {
+					continue
+				}
+				selectedChartRange = selectedChartRange.Next()
+				updateUI(active, nil)
+
+			case "o":
 				if inputPrompt != "" {
 					continue
 				}
-				inputPrompt = "Enter target level"
-				inputBuffer = ""
-				updateUI(playerData, nil)
+				togglePanel(panelOverview)
+				updateUI(active, nil)
+
+			case "v":
+				if inputPrompt != "" {
+					continue
+				}
+				cycleComparePlayer()
+				ui.Clear()
+				updateUI(active, nil)
+
+			case "f":
+				if inputPrompt != "" {
+					continue
+				}
+				eventFilter = nextEventFilter(eventFilter)
+				eventsPage = 0
+				updateUI(active, nil)
 
 			case "<C-r>":
+				active.NextUpdate = time.Now()
 				updateTicker.Reset(0)
 
+			case "s":
+				if inputPrompt != "" {
+					continue
+				}
+				togglePanel(panelSession)
+				updateUI(active, nil)
+
+			case "w":
+				if inputPrompt != "" {
+					continue
+				}
+				selectedGainWindow = selectedGainWindow.Next()
+				for _, t := range trackedPlayers {
+					t.updateGains()
+				}
+				updateUI(active, nil)
+
 			case "<Down>":
+				if playerData == nil {
+					continue
+				}
+
 				selectedMetric++
 				if selectedMetric >= len(playerData.SkillValues) {
 					selectedMetric = len(playerData.SkillValues) - 1
 				}
-				updateUI(playerData, nil)
+				updateUI(active, nil)
 
 			case "<Enter>":
 				if inputPrompt == "" {
 					continue
 				}
 
-				var tlvl int
-
-				inputPrompt = ""
-				if inputBuffer != "" {
-					tlvl, err = strconv.Atoi(inputBuffer)
-				}
+				action := inputAction
+				inputPrompt, inputAction = "", nil
 
+				err := action(strings.TrimSpace(inputBuffer))
 				if err == nil {
-					if tlvl < playerData.SkillValues[selectedMetric].Level {
-						tlvl = 0
+					if err = active.saveGoals(); err != nil {
+						log.WithError(err).Error("Unable to store goals")
 					}
-					playerData.SkillValues[selectedMetric].TargetLevel = tlvl
 				}
 
-				updateUI(playerData, err)
+				updateUI(active, err)
 
 			case "<Escape>":
-				inputPrompt = ""
-				updateUI(playerData, nil)
+				inputPrompt, inputAction = "", nil
+				updateUI(active, nil)
 
 			case "<PageDown>":
 				eventsPage++
-				updateUI(playerData, nil)
+				updateUI(active, nil)
 
 			case "<PageUp>":
 				eventsPage--
-				updateUI(playerData, nil)
+				updateUI(active, nil)
 
 			case "<Resize>":
 				ui.Clear()
-				updateUI(playerData, nil)
+				updateUI(active, nil)
+
+			case "<Tab>", "<Backspace>":
+				if inputPrompt != "" {
+					continue
+				}
+				if evt.ID == "<Tab>" {
+					switchPlayer(1)
+				} else {
+					switchPlayer(-1)
+				}
+				eventsPage = 0
+				ui.Clear()
+				updateUI(activePlayer, activePlayer.FetchErr)
 
 			case "<Up>":
 				selectedMetric--
 				if selectedMetric < 0 {
 					selectedMetric = 0
 				}
-				updateUI(playerData, nil)
+				updateUI(active, nil)
+
+			}
 
+		case <-spinnerTicker.C:
+			if activePlayer.Fetching {
+				spinnerFrame = (spinnerFrame + 1) % len(spinnerFrames)
+				updateUI(activePlayer, activePlayer.FetchErr)
 			}
 
 		case <-updateTicker.C:
-			if playerData, err = getPlayerInfo(player, 20); err != nil {
-				log.WithError(err).Error("Unable to fetch metrics")
+			for _, t := range trackedPlayers {
+				if t.Fetching || t.NextUpdate.After(time.Now()) {
+					// Either not due or there is already a fetch in
+					// flight, its result will schedule the next update
+					continue
+				}
+
+				if err := t.reloadGoals(); err != nil {
+					log.WithError(err).Error("Unable to reload goals")
+				}
+
+				t.Fetching = true
+				fetchRequests <- t
 			}
 
-			if err := updateUI(playerData, err); err != nil {
-				log.WithError(err).Error("Unable to update UI")
-				return
+			updateTicker.Reset(nextUpdate())
+			updateUI(activePlayer, activePlayer.FetchErr)
+
+		case res := <-fetchResults:
+			var (
+				err = res.Err
+				t   = res.Target
+			)
+
+			t.Fetching = false
+			t.FetchErr = err
+			t.NextUpdate = cron.Next(time.Now())
+
+			switch {
+
+			case err == nil:
+				t.FetchRetry.reset()
+				sendNotifications(detectNotifications(t.Data, res.Player))
+
+				dataLock.Lock()
+				t.Data = res.Player
+				dataLock.Unlock()
+
+				t.Sessions.Observe(history.NewSnapshot(t.Data, time.Now()))
+				t.updateGains()
+				t.updateRates()
+
+				if completed := t.completeGoals(t.Data); len(completed) > 0 {
+					for _, g := range completed {
+						log.WithField("player", t.Name).WithField("goal", g.String()).Info("Goal completed")
+					}
+					sendNotifications(goalNotifications(t.Data.Name, completed))
+					if err := t.saveGoals(); err != nil {
+						log.WithError(err).Error("Unable to store goals")
+					}
+				}
+
+			case runemetrics.IsTemporary(err):
+				log.WithError(err).WithField("player", t.Name).Error("Unable to fetch metrics")
+				if delay, ok := t.FetchRetry.next(); ok && time.Now().Add(delay).Before(t.NextUpdate) {
+					t.NextUpdate = time.Now().Add(delay)
+				} else {
+					t.FetchRetry.reset()
+				}
+
+			default:
+				log.WithError(err).WithField("player", t.Name).Error("Unable to fetch metrics")
+				t.FetchRetry.reset()
+
 			}
-			updateTicker.Reset(time.Until(cron.Next(time.Now())))
 
-			if err := playerInfoCache.storeCache(); err != nil {
-				log.WithError(err).Error("Unable to write cache")
+			if err := updateUI(activePlayer, activePlayer.FetchErr); err != nil {
+				return errors.Wrap(err, "Unable to update UI")
 			}
+			updateTicker.Reset(nextUpdate())
 
 		}
 	}
 }
 
-func updateUI(playerData *playerInfo, err error) error {
-	termWidth, termHeight := ui.TerminalDimensions()
+// nextUpdate returns the time until the next fetch of any of the
+// tracked players is due
+func nextUpdate() time.Duration {
+	var next time.Time
+	for _, t := range trackedPlayers {
+		if t.Fetching {
+			continue
+		}
+		if next.IsZero() || t.NextUpdate.Before(next) {
+			next = t.NextUpdate
+		}
+	}
+
+	if next.IsZero() {
+		// All players are being fetched, their results will schedule
+		// the next update
+		return time.Hour
+	}
+
+	return time.Until(next)
+}
+
+func updateUI(t *trackedPlayer, err error) error {
+	var (
+		playerData            = t.Data
+		termWidth, termHeight = ui.TerminalDimensions()
+	)
 
 	// Status-bar
 	status := widgets.NewParagraph()
 	status.Title = "Status"
 	status.Text = fmt.Sprintf("Last Refresh: %s | XP Change: %s | Feed Change: %s",
-		lastUpdate[updateKeyGeneral].Format("15:04:05"),
-		lastUpdate[updateKeyTotalXP].Format("15:04:05"),
-		lastUpdate[updateKeyFeed].Format("15:04:05"),
+		t.getLastUpdate(updateKeyGeneral).Format("15:04:05"),
+		t.getLastUpdate(updateKeyTotalXP).Format("15:04:05"),
+		t.getLastUpdate(updateKeyFeed).Format("15:04:05"),
 	)
 	status.SetRect(0, termHeight-3, termWidth, termHeight)
 	defer ui.Render(status)
 
 	if err != nil {
-		status.Text = fmt.Sprintf("Error: %s", err.Error())
+		status.Text = fmt.Sprintf("Error: %s", errorMessage(err))
+		if t.FetchRetry.Attempt > 0 {
+			status.Text = fmt.Sprintf("%s | %s", t.FetchRetry, status.Text)
+		}
+	}
+
+	if t.Fetching {
+		status.Text = fmt.Sprintf("%c Refreshing… | %s", spinnerFrames[spinnerFrame], status.Text)
+	}
+
+	if err != nil {
 		status.BorderStyle.Fg = ui.ColorRed
+	}
 
-		if playerData == nil {
-			return nil
-		}
+	if len(trackedPlayers) > 1 {
+		// Header needs to be shown to switch to players already fetched
+		hdrText := widgets.NewParagraph()
+		hdrText.Title = "Players (Tab / Backspace: switch, o: overview, v: compare)"
+		hdrText.Text = playerTabs()
+		hdrText.SetRect(0, 0, termWidth, 3)
+		ui.Render(hdrText)
+	}
+
+	if playerData == nil {
+		// Nothing fetched yet, there is only the status to show
+		return nil
 	}
 
 	// Header
-	hdrText := widgets.NewParagraph()
-	hdrText.Title = "Player"
-	hdrText.Text = playerData.Name
-	hdrText.SetRect(0, 0, termWidth, 3)
-	ui.Render(hdrText)
+	if len(trackedPlayers) == 1 {
+		hdrText := widgets.NewParagraph()
+		hdrText.Title = "Player"
+		hdrText.Text = playerData.Name
+		hdrText.SetRect(0, 0, termWidth, 3)
+		ui.Render(hdrText)
+	}
 
 	// General stats
 	combatLevel := widgets.NewParagraph()
@@ -238,14 +618,47 @@ func updateUI(playerData *playerInfo, err error) error {
 	ui.Render(statsGrid)
 
 	// Levels
+	levelsArea := image.Rect(0, 6, termWidth, 6+2+len(playerData.SkillValues)+1)
+	if other := comparedPlayer(t); other != nil {
+		renderComparison(playerData, other.Data, levelsArea)
+	} else {
+		renderLevels(t, levelsArea)
+	}
+
+	// Bottom panel
+	renderBottomPanel(t, image.Rect(0, 6+2+len(playerData.SkillValues)+1, termWidth, termHeight-3))
+
+	// Input box
+	if inputPrompt != "" {
+		input := widgets.NewParagraph()
+		input.Title = inputPrompt
+		input.Text = inputBuffer + "_"
+		inputTop := int(math.Floor(float64(termHeight-3)) / 2)
+		inputMargin := int(math.Floor(float64(termWidth) / 4))
+		input.SetRect(inputMargin, inputTop, termWidth-inputMargin, inputTop+3)
+		ui.Render(input)
+	}
+
+	return nil
+}
+
+func renderLevels(t *trackedPlayer, area image.Rectangle) {
+	playerData := t.Data
+
 	levelTable := widgets.NewTable()
 	levelTable.Title = "Levels"
+	if selectedMetric < len(playerData.SkillValues) {
+		s := playerData.SkillValues[selectedMetric]
+		if g, ok := t.skillGoal(s); ok {
+			levelTable.Title = fmt.Sprintf("Levels (%s target progress: %s)", s.ID, g.Mode.Label())
+		}
+	}
 	//levelTable.TextAlignment = ui.AlignRight
 	levelTable.RowStyles[0] = ui.Style{Fg: ui.ColorWhite, Modifier: ui.ModifierBold}
-	levelTable.SetRect(0, 6, termWidth, 6+2+len(playerData.SkillValues)+1)
+	levelTable.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
 	levelTable.RowSeparator = false
 
-	levelTable.ColumnWidths = []int{termWidth - 2 - 6 - 6 - 8 - 11 - 13 - 9, 6, 8, 11, 13, 9}
+	levelTable.ColumnWidths = []int{area.Dx() - 2 - 6 - 6 - 8 - 11 - 13 - 9, 6, 8, 11, 13, 9}
 
 	levelTable.Rows = [][]string{{
 		"  Skill",
@@ -255,12 +668,28 @@ func updateUI(playerData *playerInfo, err error) error {
 		fmt.Sprintf("%*s", 13, "XP remaining"),
 		fmt.Sprintf("%*s", 9, "To Level"),
 	}}
+
+	if selectedGainWindow != gainWindowOff {
+		levelTable.ColumnWidths[0] -= 13
+		levelTable.ColumnWidths = append(levelTable.ColumnWidths, 12)
+		levelTable.Rows[0] = append(levelTable.Rows[0], fmt.Sprintf("%*s", 12, selectedGainWindow.Label()))
+	}
+
+	showETA := false
+	for _, s := range playerData.SkillValues {
+		_, ok := t.skillGoal(s)
+		showETA = showETA || ok
+	}
+
+	if showETA {
+		levelTable.ColumnWidths[0] -= 15
+		levelTable.ColumnWidths = append(levelTable.ColumnWidths, 14)
+		levelTable.Rows[0] = append(levelTable.Rows[0], fmt.Sprintf("%*s", 14, "ETA"))
+	}
 	for i, s := range playerData.SkillValues {
 		var (
-			name       = s.ID.String()
-			remaining  = strconv.FormatInt(s.ID.Info().XPToNextLevel(s.XP/10), 10)
-			percentage = strconv.FormatFloat(s.ID.Info().LevelPercentage(s.XP/10), 'f', 1, 64)
-			target     = strconv.Itoa(s.Level + 1)
+			name = coloredSkillName(s.ID)
+			row  = t.newLevelRow(s)
 
 			rowStyle = ui.Style{Fg: ui.ColorWhite}
 		)
@@ -271,22 +700,31 @@ func updateUI(playerData *playerInfo, err error) error {
 			name = "  " + name
 		}
 
-		if s.TargetLevel > 0 {
-			remaining = strconv.FormatInt(s.ID.Info().XPToTargetLevel(s.TargetLevel, s.XP/10), 10)
-			percentage = strconv.FormatFloat(s.ID.Info().TargetPercentage(s.TargetLevel, s.XP/10), 'f', 1, 64)
-			target = strconv.Itoa(s.TargetLevel)
+		if row.HasGoal {
 			rowStyle.Fg = ui.ColorYellow
 		}
 
 		levelTable.Rows = append(levelTable.Rows, []string{
 			name,
-			fmt.Sprintf("%*s", 6, strconv.Itoa(s.Level)),
-			fmt.Sprintf("%*s", 8, percentage),
+			fmt.Sprintf("%*s", 6, row.Level),
+			fmt.Sprintf("%*s", 8, row.Percentage),
 			fmt.Sprintf("%*s", 11, strconv.FormatInt(s.XP/10, 10)),
-			fmt.Sprintf("%*s", 13, remaining),
-			fmt.Sprintf("%*s", 9, target),
+			fmt.Sprintf("%*s", 13, row.Remaining),
+			fmt.Sprintf("%*s", 9, row.Target),
 		})
 
+		if selectedGainWindow != gainWindowOff {
+			levelTable.Rows[i+1] = append(levelTable.Rows[i+1], fmt.Sprintf("%*s", 12, strconv.FormatInt(t.Gains[s.ID]/10, 10)))
+		}
+
+		if showETA {
+			var eta string
+			if row.HasGoal {
+				eta = t.estimateTarget(s, int(row.Goal.Target)).String()
+			}
+			levelTable.Rows[i+1] = append(levelTable.Rows[i+1], fmt.Sprintf("%*s", 14, eta))
+		}
+
 		if time.Since(s.Updated) < cfg.MarkerTime {
 			rowStyle.Fg = ui.ColorGreen
 		}
@@ -294,52 +732,123 @@ func updateUI(playerData *playerInfo, err error) error {
 		levelTable.RowStyles[i+1] = rowStyle
 	}
 	ui.Render(levelTable)
+}
+
+// prompt opens the input box, the action is called with the input
+// once it is confirmed
+func prompt(title string, action func(string) error) {
+	inputPrompt = title
+	inputAction = action
+	inputBuffer = ""
+}
+
+// handleInput edits the input buffer and tells whether the event was
+// consumed by the input box
+func handleInput(evt ui.Event) bool {
+	if evt.Type != ui.KeyboardEvent {
+		return false
+	}
+
+	switch evt.ID {
 
-	// Latest events
-	events := widgets.NewTable()
-	events.RowSeparator = false
-	events.ColumnWidths = []int{12, termWidth - 3 - 12}
-	events.SetRect(0, 6+2+len(playerData.SkillValues)+1, termWidth, termHeight-3)
+	case "<Space>":
+		inputBuffer += " "
 
-	eventsPerPage := termHeight - 3 - (6 + 2 + len(playerData.SkillValues) + 1)
-	eventPages := int(math.Ceil(float64(len(playerData.Activities)) / float64(eventsPerPage)))
+	case "<Backspace>", "<C-<Backspace>>":
+		if r := []rune(inputBuffer); len(r) > 0 {
+			inputBuffer = string(r[:len(r)-1])
+		}
+
+	default:
+		if utf8.RuneCountInString(evt.ID) != 1 {
+			return false
+		}
+		inputBuffer += evt.ID
 
-	if eventsPage < 0 {
-		eventsPage = 0
 	}
 
-	if eventsPage >= eventPages {
-		eventsPage = eventPages - 1
+	return true
+}
+
+// levelRow contains the formatted progress of a skill as shown in the
+// levels table
+type levelRow struct {
+	Level      string
+	Percentage string
+	Remaining  string
+	Target     string
+
+	Goal    goal
+	HasGoal bool
+}
+
+func (t *trackedPlayer) newLevelRow(s runemetrics.Skill) levelRow {
+	var (
+		info  = skillInfo(s.ID)
+		level = skillLevel(s)
+		row   = levelRow{
+			Remaining:  strconv.FormatInt(info.XPToNextLevel(s.XP/10), 10),
+			Percentage: strconv.FormatFloat(info.LevelPercentage(s.XP/10), 'f', 1, 64),
+		}
+	)
+
+	row.Level = strconv.Itoa(level)
+	row.Target = strconv.Itoa(level + 1)
+
+	if info.IsMaxed(s.XP / 10) {
+		row.Remaining = "-"
+		row.Target = "max"
 	}
 
-	events.Title = fmt.Sprintf("Event Log (%d / %d)", eventsPage+1, eventPages)
+	if row.Goal, row.HasGoal = t.skillGoal(s); row.HasGoal {
+		row.Remaining = strconv.FormatInt(info.XPToTargetLevel(int(row.Goal.Target), s.XP/10), 10)
+		row.Percentage = strconv.FormatFloat(info.TargetProgress(int(row.Goal.Target), s.XP/10, row.Goal.skillBaseXP(s, info)), 'f', 1, 64)
+		row.Target = strconv.FormatInt(row.Goal.Target, 10)
+	}
 
-	for i, logEntry := range playerData.Activities[eventsPage*eventsPerPage:] {
-		date, _ := logEntry.GetParsedDate()
-		events.Rows = append(
-			events.Rows,
-			[]string{
-				date.Local().Format("01/02 15:04"),
-				strings.Replace(logEntry.Details, "  ", " ", -1),
-			},
-		)
+	return row
+}
 
-		if time.Since(date) < cfg.MarkerTime {
-			events.RowStyles[i] = ui.Style{Fg: ui.ColorGreen}
+// playerTabs lists the tracked players highlighting the active one
+func playerTabs() string {
+	var tabs []string
+	for _, t := range trackedPlayers {
+		name := t.Name
+		if t.Data != nil {
+			name = t.Data.Name
 		}
+
+		if t == activePlayer {
+			name = fmt.Sprintf("[%s](mod:reverse)", name)
+		}
+		tabs = append(tabs, name)
 	}
-	ui.Render(events)
 
-	// Input box
-	if inputPrompt != "" {
-		input := widgets.NewParagraph()
-		input.Title = inputPrompt
-		input.Text = inputBuffer + "_"
-		inputTop := int(math.Floor(float64(termHeight-3)) / 2)
-		inputMargin := int(math.Floor(float64(termWidth) / 4))
-		input.SetRect(inputMargin, inputTop, termWidth-inputMargin, inputTop+3)
-		ui.Render(input)
+	return strings.Join(tabs, " | ")
+}
+
+func errorMessage(err error) string {
+	switch errors.Cause(err) {
+	case runemetrics.ErrProfilePrivate:
+		return "Profile is private, set RuneMetrics to public in the game settings"
+	case runemetrics.ErrNotAMember:
+		return "Player is not a member, RuneMetrics is only available to members"
+	case runemetrics.ErrNoProfile:
+		return "Player does not exist or has no RuneMetrics profile"
+	case runemetrics.ErrRateLimited:
+		return "RuneMetrics rate limit exceeded, try a less frequent update schedule"
+	case runemetrics.ErrServerError:
+		return "RuneMetrics is having issues, will try again later"
+	default:
+		return err.Error()
 	}
+}
 
-	return nil
+// skillInfo returns the skill information respecting the configuration
+// whether to use virtual levels
+func skillInfo(id runemetrics.SkillID) runemetrics.SkillInfo {
+	if cfg.VirtualLevels {
+		return id.Info().Virtual()
+	}
+	return id.Info()
 }
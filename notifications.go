@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/notify"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// notifySinks contains the sinks configured through --notify
+var notifySinks []notify.Sink
+
+// detectNotifications compares two states of the player and creates a
+// notification for every level-up, new activity and rank threshold
+// reached in between, without a previous state nothing is reported
+func detectNotifications(prev, p *runemetrics.PlayerInfo) []notify.Notification {
+	if prev == nil {
+		return nil
+	}
+
+	var (
+		now = time.Now()
+		out []notify.Notification
+	)
+
+	add := func(kind notify.Kind, text string, args ...interface{}) {
+		out = append(out, notify.Notification{
+			Kind:   kind,
+			Player: p.Name,
+			Text:   fmt.Sprintf(text, args...),
+			Time:   now,
+		})
+	}
+
+	for _, s := range p.SkillValues {
+		o := prev.GetSkill(s.ID)
+
+		if lvl := skillLevel(s); lvl > skillLevel(o) {
+			add(notify.KindLevelUp, "%s reached level %d in %s", p.Name, lvl, s.ID)
+		}
+
+		if th, ok := rankThreshold(o.Rank, s.Rank); ok {
+			add(notify.KindRank, "%s reached the top %d in %s (rank %d)", p.Name, th, s.ID, s.Rank)
+		}
+	}
+
+	if th, ok := rankThreshold(prev.NumericRank(), p.NumericRank()); ok {
+		add(notify.KindRank, "%s reached the top %d overall (rank %d)", p.Name, th, p.NumericRank())
+	}
+
+	for _, a := range newActivities(prev, p) {
+		ev := a.Event()
+		if ev.Type == runemetrics.EventLevelUp && notifyKindEnabled(notify.KindLevelUp) {
+			// Already reported by the level-up notification
+			continue
+		}
+
+		add(notify.KindActivity, "%s: %s", p.Name, a.Text)
+		out[len(out)-1].Event = string(ev.Type)
+	}
+
+	return out
+}
+
+// goalNotifications creates a notification for every completed goal
+func goalNotifications(player string, completed []goal) []notify.Notification {
+	var out []notify.Notification
+	for _, g := range completed {
+		out = append(out, notify.Notification{
+			Kind:   notify.KindGoalCompleted,
+			Player: player,
+			Text:   fmt.Sprintf("%s completed the goal: %s", player, g),
+			Time:   g.Completed,
+		})
+	}
+	return out
+}
+
+// rankThreshold returns the best configured rank threshold passed when
+// the rank changed from prev to cur, unranked players pass none
+func rankThreshold(prev, cur int64) (int64, bool) {
+	var (
+		best  int64
+		found bool
+	)
+
+	if prev == 0 || cur == 0 {
+		return 0, false
+	}
+
+	for _, th := range cfg.NotifyRanks {
+		t := int64(th)
+		if cur <= t && prev > t && (!found || t < best) {
+			best, found = t, true
+		}
+	}
+
+	return best, found
+}
+
+func notifyKindEnabled(kind notify.Kind) bool {
+	for _, k := range cfg.NotifyKinds {
+		if notify.Kind(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// sendNotifications delivers the notifications of the enabled kinds to
+// all sinks in the background
+func sendNotifications(ns []notify.Notification) {
+	var enabled []notify.Notification
+	for _, n := range ns {
+		if notifyKindEnabled(n.Kind) {
+			enabled = append(enabled, n)
+		}
+	}
+
+	if len(enabled) == 0 || len(notifySinks) == 0 {
+		return
+	}
+
+	go func() {
+		for _, n := range enabled {
+			for _, s := range notifySinks {
+				if err := s.Send(n); err != nil {
+					log.WithError(err).WithField("kind", n.Kind).Error("Unable to send notification")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Luzifer/runemetrics/pkg/notify"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func TestRankThreshold(t *testing.T) {
+	defer func(v []int) { cfg.NotifyRanks = v }(cfg.NotifyRanks)
+	cfg.NotifyRanks = []int{100, 1000, 10000}
+
+	for _, tc := range []struct {
+		Prev, Cur int64
+		Want      int64
+		WantOK    bool
+	}{
+		{Prev: 1200, Cur: 900, Want: 1000, WantOK: true},
+		{Prev: 20000, Cur: 50, Want: 100, WantOK: true},
+		{Prev: 1000, Cur: 999, WantOK: false},
+		{Prev: 1001, Cur: 1000, Want: 1000, WantOK: true},
+		{Prev: 900, Cur: 800, WantOK: false},
+		{Prev: 900, Cur: 1200, WantOK: false},
+		{Prev: 0, Cur: 50, WantOK: false},
+		{Prev: 50, Cur: 0, WantOK: false},
+	} {
+		got, ok := rankThreshold(tc.Prev, tc.Cur)
+		if ok != tc.WantOK || got != tc.Want {
+			t.Errorf("rankThreshold(%d, %d) = %d, %v, want %d, %v", tc.Prev, tc.Cur, got, ok, tc.Want, tc.WantOK)
+		}
+	}
+}
+
+func TestDetectNotifications(t *testing.T) {
+	defer func(v []int) { cfg.NotifyRanks = v }(cfg.NotifyRanks)
+	cfg.NotifyRanks = []int{1000}
+
+	var (
+		oldQuest = runemetrics.Activity{Text: "Quest complete: Cook's Assistant", Details: "I completed the quest Cook's Assistant."}
+		newQuest = runemetrics.Activity{Text: "Quest complete: Demon Slayer", Details: "I completed the quest Demon Slayer."}
+	)
+
+	prev := &runemetrics.PlayerInfo{
+		Name:       "Tester",
+		Activities: []runemetrics.Activity{oldQuest},
+		SkillValues: []runemetrics.Skill{
+			{ID: 0, Level: 98, XP: 120000000, Rank: 1500},
+			{ID: 1, Level: 50, XP: 1012340, Rank: 20000},
+		},
+	}
+
+	for _, tc := range []struct {
+		Name string
+		Cur  *runemetrics.PlayerInfo
+		Want []notify.Kind
+	}{
+		{
+			Name: "unchanged",
+			Cur:  prev,
+		},
+		{
+			Name: "level-up and rank",
+			Cur: &runemetrics.PlayerInfo{
+				Name:       "Tester",
+				Activities: []runemetrics.Activity{oldQuest},
+				SkillValues: []runemetrics.Skill{
+					{ID: 0, Level: 99, XP: 130344310, Rank: 900},
+					{ID: 1, Level: 50, XP: 1012340, Rank: 20000},
+				},
+			},
+			Want: []notify.Kind{notify.KindLevelUp, notify.KindRank},
+		},
+		{
+			Name: "new activity",
+			Cur: &runemetrics.PlayerInfo{
+				Name:        "Tester",
+				Activities:  []runemetrics.Activity{newQuest, oldQuest},
+				SkillValues: prev.SkillValues,
+			},
+			Want: []notify.Kind{notify.KindActivity},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := detectNotifications(prev, tc.Cur)
+
+			var kinds []notify.Kind
+			for _, n := range got {
+				kinds = append(kinds, n.Kind)
+			}
+
+			if len(kinds) != len(tc.Want) {
+				t.Fatalf("kinds = %v, want %v", kinds, tc.Want)
+			}
+			for i := range kinds {
+				if kinds[i] != tc.Want[i] {
+					t.Errorf("kinds = %v, want %v", kinds, tc.Want)
+				}
+			}
+		})
+	}
+
+	if n := detectNotifications(nil, prev); n != nil {
+		t.Errorf("detectNotifications(nil, p) = %v, want nil", n)
+	}
+}
+
+func TestDetectNotificationsLevelUpActivity(t *testing.T) {
+	defer func(v []string) { cfg.NotifyKinds = v }(cfg.NotifyKinds)
+
+	var (
+		levelUp = runemetrics.Activity{Text: "Levelled up Attack.", Details: "I levelled my  Attack skill, I am now level 99."}
+		prev    = &runemetrics.PlayerInfo{
+			Name:        "Tester",
+			SkillValues: []runemetrics.Skill{{ID: 0, Level: 98, XP: 120000000}},
+		}
+		cur = &runemetrics.PlayerInfo{
+			Name:        "Tester",
+			Activities:  []runemetrics.Activity{levelUp},
+			SkillValues: []runemetrics.Skill{{ID: 0, Level: 99, XP: 130344310}},
+		}
+	)
+
+	for _, tc := range []struct {
+		Kinds []string
+		Want  []notify.Kind
+	}{
+		// The level-up notification already reports the activity
+		{Kinds: []string{"activity", "level_up"}, Want: []notify.Kind{notify.KindLevelUp}},
+		{Kinds: []string{"activity"}, Want: []notify.Kind{notify.KindLevelUp, notify.KindActivity}},
+	} {
+		cfg.NotifyKinds = tc.Kinds
+
+		var kinds []notify.Kind
+		for _, n := range detectNotifications(prev, cur) {
+			kinds = append(kinds, n.Kind)
+			if n.Kind == notify.KindActivity && n.Event != string(runemetrics.EventLevelUp) {
+				t.Errorf("Event = %q, want %q", n.Event, runemetrics.EventLevelUp)
+			}
+		}
+
+		if len(kinds) != len(tc.Want) {
+			t.Errorf("kinds with %v enabled = %v, want %v", tc.Kinds, kinds, tc.Want)
+			continue
+		}
+		for i := range kinds {
+			if kinds[i] != tc.Want[i] {
+				t.Errorf("kinds with %v enabled = %v, want %v", tc.Kinds, kinds, tc.Want)
+			}
+		}
+	}
+}
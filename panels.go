@@ -0,0 +1,380 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+type bottomPanel int
+
+const (
+	panelEvents bottomPanel = iota
+	panelSession
+	panelCharts
+	panelGoals
+	panelOverview
+)
+
+var (
+	selectedPanel = panelEvents
+
+	// eventFilter limits the event log to one event type, empty to show
+	// all events
+	eventFilter runemetrics.EventType
+)
+
+// togglePanel switches to the given panel or back to the event log if
+// the panel is already shown
+func togglePanel(p bottomPanel) {
+	if selectedPanel == p {
+		selectedPanel = panelEvents
+		return
+	}
+	selectedPanel = p
+}
+
+func renderBottomPanel(t *trackedPlayer, area image.Rectangle) {
+	switch selectedPanel {
+	case panelCharts:
+		renderCharts(t, area)
+	case panelGoals:
+		renderGoals(t, area)
+	case panelOverview:
+		renderOverview(area)
+	case panelSession:
+		renderSession(t, area)
+	default:
+		renderEventLog(t.Data, area)
+	}
+}
+
+func renderEventLog(playerData *runemetrics.PlayerInfo, area image.Rectangle) {
+	events := widgets.NewTable()
+	events.RowSeparator = false
+	events.ColumnWidths = []int{12, 15, area.Dx() - 4 - 12 - 15}
+	events.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+
+	activities := filterActivities(playerData.Activities, eventFilter)
+
+	eventsPerPage := area.Dy()
+	if eventsPerPage < 1 {
+		// Terminal is too small to show any events, still paginate
+		eventsPerPage = 1
+	}
+
+	var eventPages int
+	eventsPage, eventPages = clampPage(eventsPage, len(activities), eventsPerPage)
+
+	filter := "f: filter"
+	if eventFilter != "" {
+		filter = fmt.Sprintf("%s: %d, f: filter", eventFilter.Label(), countEvents(activities)[eventFilter])
+	}
+
+	events.Title = fmt.Sprintf("Event Log (%s) (%d / %d)", filter, eventsPage+1, eventPages)
+
+	if len(activities) == 0 {
+		events.Title = fmt.Sprintf("Event Log (%s)", filter)
+		events.Rows = [][]string{{"", "", "No activities yet"}}
+	}
+
+	for i, logEntry := range activities[eventsPage*eventsPerPage:] {
+		date, _ := logEntry.GetParsedDate()
+		events.Rows = append(
+			events.Rows,
+			[]string{
+				date.Local().Format("01/02 15:04"),
+				logEntry.Event().Type.Label(),
+				strings.Replace(logEntry.Details, "  ", " ", -1),
+			},
+		)
+
+		if time.Since(date) < cfg.MarkerTime {
+			events.RowStyles[i] = ui.Style{Fg: ui.ColorGreen}
+		}
+	}
+	ui.Render(events)
+}
+
+// clampPage limits the page to the pages required to show the entries
+// with perPage (> 0) entries per page and returns it together with the
+// number of pages, without entries the first page is returned
+func clampPage(page, entries, perPage int) (int, int) {
+	pages := int(math.Ceil(float64(entries) / float64(perPage)))
+
+	if page >= pages {
+		page = pages - 1
+	}
+
+	if page < 0 {
+		page = 0
+	}
+
+	return page, pages
+}
+
+// nextEventFilter returns the next event type to filter the event log
+// by, after the last type the filter is disabled again
+func nextEventFilter(f runemetrics.EventType) runemetrics.EventType {
+	if f == "" {
+		return runemetrics.EventTypes[0]
+	}
+
+	for i, t := range runemetrics.EventTypes {
+		if t == f && i+1 < len(runemetrics.EventTypes) {
+			return runemetrics.EventTypes[i+1]
+		}
+	}
+
+	return ""
+}
+
+// filterActivities returns the activities of the given event type or
+// all activities if no type is given
+func filterActivities(activities []runemetrics.Activity, t runemetrics.EventType) []runemetrics.Activity {
+	if t == "" {
+		return activities
+	}
+
+	var out []runemetrics.Activity
+	for _, a := range activities {
+		if a.Event().Type == t {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// countEvents counts the activities by event type, boss kills are
+// counted by the number of bosses killed
+func countEvents(activities []runemetrics.Activity) map[runemetrics.EventType]int {
+	out := map[runemetrics.EventType]int{}
+	for _, a := range activities {
+		ev := a.Event()
+		if ev.Type == runemetrics.EventBossKill {
+			out[ev.Type] += ev.Count
+			continue
+		}
+		out[ev.Type]++
+	}
+	return out
+}
+
+// eventSummary describes the event counts in the order of the event types
+func eventSummary(counts map[runemetrics.EventType]int) string {
+	var parts []string
+	for _, t := range runemetrics.EventTypes {
+		if counts[t] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", t.Label(), counts[t]))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func renderSession(t *trackedPlayer, area image.Rectangle) {
+	table := widgets.NewTable()
+	table.RowSeparator = false
+	table.ColumnWidths = []int{area.Dx() - 2 - 2 - 13 - 11, 13, 11}
+	table.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+	table.RowStyles[0] = ui.Style{Fg: ui.ColorWhite, Modifier: ui.ModifierBold}
+
+	now := time.Now()
+	session, active := t.Sessions.Latest(now)
+
+	switch {
+	case session == nil:
+		table.Title = "Session (none detected yet)"
+	case active:
+		table.Title = fmt.Sprintf("Session (active since %s)", session.Start.Local().Format("15:04"))
+	default:
+		table.Title = fmt.Sprintf("Session (%s - %s)",
+			session.Start.Local().Format("01/02 15:04"),
+			session.LastChange.Local().Format("15:04"))
+	}
+
+	if session == nil {
+		table.Rows = [][]string{{"  No XP gains observed yet", "", ""}}
+		ui.Render(table)
+		return
+	}
+
+	duration := session.Duration(now, active)
+
+	table.Rows = [][]string{
+		{
+			"  Skill",
+			fmt.Sprintf("%*s", 13, "XP gained"),
+			fmt.Sprintf("%*s", 11, "XP / h"),
+		},
+		{
+			fmt.Sprintf("  Overall (%s)", duration.Round(time.Minute)),
+			fmt.Sprintf("%*s", 13, strconv.FormatInt(session.TotalGained()/10, 10)),
+			fmt.Sprintf("%*s", 11, xpPerHour(float64(session.TotalGained())/duration.Hours())),
+		},
+	}
+	table.RowStyles[1] = ui.Style{Fg: ui.ColorYellow}
+
+	var skills []runemetrics.SkillID
+	for id, gained := range session.Gained {
+		if gained > 0 {
+			skills = append(skills, id)
+		}
+	}
+	sort.Slice(skills, func(i, j int) bool { return session.Gained[skills[i]] > session.Gained[skills[j]] })
+
+	for _, id := range skills {
+		table.Rows = append(table.Rows, []string{
+			"  " + coloredSkillName(id),
+			fmt.Sprintf("%*s", 13, strconv.FormatInt(session.Gained[id]/10, 10)),
+			fmt.Sprintf("%*s", 11, xpPerHour(session.XPPerHour(id, duration))),
+		})
+	}
+
+	ui.Render(table)
+}
+
+func renderGoals(t *trackedPlayer, area image.Rectangle) {
+	table := widgets.NewTable()
+	table.Title = "Goals (n: new, d: delete)"
+	table.RowSeparator = false
+	table.ColumnWidths = []int{4, area.Dx() - 2 - 6 - 4 - 9 - 16 - 11 - 16, 9, 16, 11, 16}
+	table.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+	table.RowStyles[0] = ui.Style{Fg: ui.ColorWhite, Modifier: ui.ModifierBold}
+
+	table.Rows = [][]string{{
+		fmt.Sprintf("%*s", 4, "ID"),
+		"Goal",
+		fmt.Sprintf("%*s", 9, "Progress"),
+		fmt.Sprintf("%*s", 16, "Remaining"),
+		fmt.Sprintf("%*s", 11, "Deadline"),
+		fmt.Sprintf("%*s", 16, "Required / day"),
+	}}
+
+	dataLock.RLock()
+	defer dataLock.RUnlock()
+
+	if len(t.Goals) == 0 {
+		table.Rows = append(table.Rows, []string{"", "No goals yet", "", "", "", ""})
+	}
+
+	for i, g := range t.Goals {
+		var (
+			deadline = "-"
+			daily    = "-"
+			progress = g.progress(t.Data)
+			rowStyle = ui.Style{Fg: ui.ColorWhite}
+		)
+
+		if !g.Deadline.IsZero() {
+			deadline = g.Deadline.Local().Format(goalDeadlineFormat)
+
+			if d, ok := progress.Daily(g.Deadline); ok {
+				daily = fmt.Sprintf("%d %s", d, progress.Unit)
+			} else if !progress.Done() {
+				daily = "overdue"
+				rowStyle.Fg = ui.ColorRed
+			}
+		}
+
+		if !g.Completed.IsZero() {
+			daily = "done " + g.Completed.Local().Format("01/02 15:04")
+			rowStyle.Fg = ui.ColorGreen
+		}
+
+		table.Rows = append(table.Rows, []string{
+			fmt.Sprintf("%*d", 4, g.ID),
+			g.String(),
+			fmt.Sprintf("%*s", 9, strconv.FormatFloat(progress.Percentage(), 'f', 1, 64)+"%"),
+			fmt.Sprintf("%*s", 16, fmt.Sprintf("%d %s", progress.Remaining(), progress.Unit)),
+			fmt.Sprintf("%*s", 11, deadline),
+			fmt.Sprintf("%*s", 16, daily),
+		})
+		table.RowStyles[i+1] = rowStyle
+	}
+
+	ui.Render(table)
+}
+
+// renderOverview compares the tracked players
+func renderOverview(area image.Rectangle) {
+	table := widgets.NewTable()
+	table.Title = "Overview (Tab: switch player)"
+	table.RowSeparator = false
+	table.ColumnWidths = []int{area.Dx() - 2 - 6 - 12 - 13 - 12 - 12 - 12 - 10, 12, 13, 12, 12, 12, 10}
+	table.SetRect(area.Min.X, area.Min.Y, area.Max.X, area.Max.Y)
+	table.RowStyles[0] = ui.Style{Fg: ui.ColorWhite, Modifier: ui.ModifierBold}
+
+	table.Rows = [][]string{{
+		"  Player",
+		fmt.Sprintf("%*s", 12, "Total Level"),
+		fmt.Sprintf("%*s", 13, "Total XP"),
+		fmt.Sprintf("%*s", 12, gainWindowSession.Label()),
+		fmt.Sprintf("%*s", 12, gainWindowToday.Label()),
+		fmt.Sprintf("%*s", 12, gainWindowWeek.Label()),
+		fmt.Sprintf("%*s", 10, "Status"),
+	}}
+
+	for i, t := range trackedPlayers {
+		var (
+			name     = "  " + t.Name
+			level    = "-"
+			xp       = "-"
+			status   = "-"
+			rowStyle = ui.Style{Fg: ui.ColorWhite}
+		)
+
+		if t == activePlayer {
+			name = "> " + t.Name
+		}
+
+		if t.Data != nil {
+			name = strings.Replace(name, t.Name, t.Data.Name, 1)
+			level = strconv.FormatInt(t.Data.TotalSkill, 10)
+			xp = strconv.FormatInt(t.Data.TotalXP, 10)
+			status = "offline"
+			if t.Data.LoggedIn {
+				status = "online"
+				rowStyle.Fg = ui.ColorGreen
+			}
+		}
+
+		if t.FetchErr != nil {
+			status = "error"
+			rowStyle.Fg = ui.ColorRed
+		}
+
+		table.Rows = append(table.Rows, []string{
+			name,
+			fmt.Sprintf("%*s", 12, level),
+			fmt.Sprintf("%*s", 13, xp),
+			fmt.Sprintf("%*s", 12, strconv.FormatInt(t.TotalGains[gainWindowSession], 10)),
+			fmt.Sprintf("%*s", 12, strconv.FormatInt(t.TotalGains[gainWindowToday], 10)),
+			fmt.Sprintf("%*s", 12, strconv.FormatInt(t.TotalGains[gainWindowWeek], 10)),
+			fmt.Sprintf("%*s", 10, status),
+		})
+		table.RowStyles[i+1] = rowStyle
+	}
+
+	ui.Render(table)
+}
+
+// xpPerHour formats a rate given in tenths of XP per hour
+func xpPerHour(rate float64) string {
+	if math.IsNaN(rate) || math.IsInf(rate, 0) {
+		return "-"
+	}
+	return strconv.FormatInt(int64(rate/10), 10)
+}
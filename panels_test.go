@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestClampPage(t *testing.T) {
+	for _, tc := range []struct {
+		Name      string
+		Page      int
+		Entries   int
+		PerPage   int
+		WantPage  int
+		WantPages int
+	}{
+		{Name: "no entries", Page: 0, Entries: 0, PerPage: 10, WantPage: 0, WantPages: 0},
+		{Name: "no entries after paging", Page: 3, Entries: 0, PerPage: 10, WantPage: 0, WantPages: 0},
+		{Name: "negative page", Page: -1, Entries: 5, PerPage: 10, WantPage: 0, WantPages: 1},
+		{Name: "within range", Page: 1, Entries: 25, PerPage: 10, WantPage: 1, WantPages: 3},
+		{Name: "exact pages", Page: 2, Entries: 20, PerPage: 10, WantPage: 1, WantPages: 2},
+		{Name: "beyond last page", Page: 7, Entries: 25, PerPage: 10, WantPage: 2, WantPages: 3},
+		{Name: "one entry per page", Page: 4, Entries: 3, PerPage: 1, WantPage: 2, WantPages: 3},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			page, pages := clampPage(tc.Page, tc.Entries, tc.PerPage)
+			if page != tc.WantPage || pages != tc.WantPages {
+				t.Errorf("clampPage(%d, %d, %d) = (%d, %d), want (%d, %d)",
+					tc.Page, tc.Entries, tc.PerPage, page, pages, tc.WantPage, tc.WantPages)
+			}
+		})
+	}
+}
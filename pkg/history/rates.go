@@ -0,0 +1,99 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// Rates contains the XP gained within a time window together with the
+// time played in that window to derive XP rates from
+type Rates struct {
+	// Gained contains the XP (in tenths) gained per skill while playing,
+	// gains over gaps longer than the idle gap are not included
+	Gained map[runemetrics.SkillID]int64
+	// Total contains the XP (in tenths) gained per skill in the window
+	// including the gains over gaps
+	Total map[runemetrics.SkillID]int64
+	// Samples contains the number of snapshots with gains per skill
+	Samples map[runemetrics.SkillID]int
+	// PlayTime is the time covered by snapshots with XP changes not
+	// being further apart than the idle gap
+	PlayTime time.Duration
+	// Span is the time between the baseline and the latest snapshot
+	Span time.Duration
+}
+
+// Rates calculates the XP rates from the snapshots taken since the
+// given time. Gaps between two snapshots longer than idleGap (i.e. the
+// program was not running) are not counted as play time and the XP
+// gained over them is not counted towards the play rates.
+func (s *Store) Rates(since time.Time, idleGap time.Duration) (Rates, error) {
+	out := Rates{
+		Gained:  map[runemetrics.SkillID]int64{},
+		Samples: map[runemetrics.SkillID]int{},
+		Total:   map[runemetrics.SkillID]int64{},
+	}
+
+	base, err := s.Baseline(since)
+	if err != nil || base == nil {
+		return out, err
+	}
+
+	snaps, err := s.Snapshots(base.Time, maxTime)
+	if err != nil {
+		return out, err
+	}
+
+	for i := 1; i < len(snaps); i++ {
+		var (
+			prev, curr = snaps[i-1], snaps[i]
+			gap        = curr.Time.Sub(prev.Time)
+			changed    bool
+		)
+
+		for _, sk := range curr.Skills {
+			gain := sk.XP - prev.Skill(sk.ID).XP
+			if gain <= 0 {
+				continue
+			}
+
+			out.Total[sk.ID] += gain
+			if gap > idleGap {
+				// Gained while not observed, the play time is unknown
+				continue
+			}
+
+			out.Gained[sk.ID] += gain
+			out.Samples[sk.ID]++
+			changed = true
+		}
+
+		if changed {
+			out.PlayTime += gap
+		}
+	}
+
+	if len(snaps) > 0 {
+		out.Span = snaps[len(snaps)-1].Time.Sub(base.Time)
+	}
+
+	return out, nil
+}
+
+// XPPerHour returns the XP (in tenths) gained per hour of play
+func (r Rates) XPPerHour(id runemetrics.SkillID) float64 {
+	if r.PlayTime <= 0 {
+		return 0
+	}
+	return float64(r.Gained[id]) / r.PlayTime.Hours()
+}
+
+// XPPerCalendarHour returns the XP (in tenths) gained per hour passed
+// regardless whether the player was playing
+func (r Rates) XPPerCalendarHour(id runemetrics.SkillID) float64 {
+	if r.Span <= 0 {
+		return 0
+	}
+	return float64(r.Total[id]) / r.Span.Hours()
+}
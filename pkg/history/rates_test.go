@@ -0,0 +1,61 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+func TestRatesSkipOfflineGaps(t *testing.T) {
+	s, cleanup := openTestStore(t)
+	defer cleanup()
+
+	var (
+		start = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		xp    int64
+	)
+
+	record := func(at time.Time) {
+		if _, err := s.Record(testSnapshot(at, xp)); err != nil {
+			t.Fatalf("Unable to record snapshot: %s", err)
+		}
+	}
+
+	// 10 minutes of play gaining 1,000 XP per minute
+	for i := 0; i <= 10; i++ {
+		record(start.Add(time.Duration(i) * time.Minute))
+		xp += 10000
+	}
+	xp -= 10000
+
+	// 10 hours offline gaining 6,000,000 XP
+	offline := start.Add(10*time.Minute + 10*time.Hour)
+	xp += 60000000
+	record(offline)
+
+	r, err := s.Rates(start, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Unable to calculate rates: %s", err)
+	}
+
+	if r.PlayTime != 10*time.Minute {
+		t.Errorf("PlayTime = %s, want 10m", r.PlayTime)
+	}
+
+	if want := int64(100000); r.Gained[0] != want {
+		t.Errorf("Gained = %d, want %d", r.Gained[0], want)
+	}
+
+	if want := int64(100000 + 60000000); r.Total[0] != want {
+		t.Errorf("Total = %d, want %d", r.Total[0], want)
+	}
+
+	if rate := r.XPPerHour(0) / 10; rate != 60000 {
+		t.Errorf("XPPerHour = %f XP, want 60000 XP", rate)
+	}
+
+	if r.Samples[runemetrics.SkillID(0)] != 10 {
+		t.Errorf("Samples = %d, want 10", r.Samples[0])
+	}
+}
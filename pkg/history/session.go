@@ -0,0 +1,139 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// Session describes a period of continuous XP gains
+type Session struct {
+	Start      time.Time
+	LastChange time.Time
+	// Baseline is the state of the player when the session started
+	Baseline Snapshot
+	// Gained contains the XP (in tenths) gained per skill
+	Gained map[runemetrics.SkillID]int64
+}
+
+// TotalGained returns the XP (in tenths) gained in all skills
+func (s Session) TotalGained() int64 {
+	var sum int64
+	for _, g := range s.Gained {
+		sum += g
+	}
+	return sum
+}
+
+// Duration returns the time between the start of the session and the
+// given time (limited to the last XP change for ended sessions)
+func (s Session) Duration(now time.Time, active bool) time.Duration {
+	if !active {
+		now = s.LastChange
+	}
+	return now.Sub(s.Start)
+}
+
+// XPPerHour returns the XP (in tenths) per hour gained in the skill
+// during the session
+func (s Session) XPPerHour(id runemetrics.SkillID, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(s.Gained[id]) / d.Hours()
+}
+
+// SessionTracker detects play sessions from consecutive snapshots: a
+// session starts with the first XP change after being idle and ends
+// after no XP changed for the idle time or the player logged out
+type SessionTracker struct {
+	IdleTime time.Duration
+
+	current  *Session
+	previous *Session
+	last     *Snapshot
+}
+
+// NewSessionTracker creates a tracker ending sessions after idleTime
+// without XP changes
+func NewSessionTracker(idleTime time.Duration) *SessionTracker {
+	return &SessionTracker{IdleTime: idleTime}
+}
+
+// Observe feeds the next snapshot into the tracker. Snapshots must be
+// observed in chronological order.
+func (t *SessionTracker) Observe(snap Snapshot) {
+	defer func() { t.last = &snap }()
+
+	if t.last == nil {
+		return
+	}
+
+	if t.current != nil {
+		loggedOut := t.last.LoggedIn && !snap.LoggedIn
+		if loggedOut || snap.Time.Sub(t.current.LastChange) > t.IdleTime {
+			t.previous, t.current = t.current, nil
+		}
+	}
+
+	var changed bool
+	for _, sk := range snap.Skills {
+		if sk.XP > t.last.Skill(sk.ID).XP {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	if t.current == nil && snap.Time.Sub(t.last.Time) > t.IdleTime {
+		// XP was gained while not being observed (i.e. the program was
+		// not running), as it is unknown when it was gained the session
+		// starts with this snapshot and counts the following gains only
+		t.current = &Session{
+			Start:      snap.Time,
+			LastChange: snap.Time,
+			Baseline:   snap,
+			Gained:     map[runemetrics.SkillID]int64{},
+		}
+		return
+	}
+
+	if t.current == nil {
+		// Playing started somewhere between the last snapshot and this one
+		t.current = &Session{
+			Start:    t.last.Time,
+			Baseline: *t.last,
+			Gained:   map[runemetrics.SkillID]int64{},
+		}
+	}
+
+	for _, sk := range snap.Skills {
+		t.current.Gained[sk.ID] = sk.XP - t.current.Baseline.Skill(sk.ID).XP
+	}
+	t.current.LastChange = snap.Time
+}
+
+// Current returns the active session at the given time or nil
+func (t *SessionTracker) Current(now time.Time) *Session {
+	if t.current == nil || now.Sub(t.current.LastChange) > t.IdleTime {
+		return nil
+	}
+	return t.current
+}
+
+// Latest returns the active session or the last ended one, the bool
+// tells whether the session is still active
+func (t *SessionTracker) Latest(now time.Time) (*Session, bool) {
+	if s := t.Current(now); s != nil {
+		return s, true
+	}
+
+	if t.current != nil {
+		return t.current, false
+	}
+
+	return t.previous, false
+}
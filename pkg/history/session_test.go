@@ -0,0 +1,71 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionTracker(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		Name      string
+		Snapshots []Snapshot
+		WantStart time.Time
+		WantGain  int64
+	}{
+		{
+			Name: "continuous play",
+			Snapshots: []Snapshot{
+				testSnapshot(start, 0),
+				testSnapshot(start.Add(time.Minute), 10000),
+				testSnapshot(start.Add(2*time.Minute), 20000),
+			},
+			WantStart: start,
+			WantGain:  20000,
+		},
+		{
+			Name: "play after idle time",
+			Snapshots: []Snapshot{
+				testSnapshot(start, 0),
+				testSnapshot(start.Add(time.Minute), 10000),
+				testSnapshot(start.Add(time.Hour), 10000),
+				testSnapshot(start.Add(time.Hour+time.Minute), 30000),
+			},
+			WantStart: start.Add(time.Hour),
+			WantGain:  20000,
+		},
+		{
+			Name: "offline gap",
+			Snapshots: []Snapshot{
+				testSnapshot(start, 0),
+				testSnapshot(start.Add(10*time.Hour), 60000000),
+				testSnapshot(start.Add(10*time.Hour+time.Minute), 60010000),
+				testSnapshot(start.Add(10*time.Hour+2*time.Minute), 60020000),
+			},
+			WantStart: start.Add(10 * time.Hour),
+			WantGain:  20000,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			tracker := NewSessionTracker(15 * time.Minute)
+			for _, snap := range tc.Snapshots {
+				tracker.Observe(snap)
+			}
+
+			now := tc.Snapshots[len(tc.Snapshots)-1].Time
+			s, active := tracker.Latest(now)
+			if s == nil || !active {
+				t.Fatalf("Latest() = %v, %v, want active session", s, active)
+			}
+
+			if !s.Start.Equal(tc.WantStart) {
+				t.Errorf("Start = %s, want %s", s.Start, tc.WantStart)
+			}
+
+			if s.TotalGained() != tc.WantGain {
+				t.Errorf("TotalGained() = %d, want %d", s.TotalGained(), tc.WantGain)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package history
+
+import (
+	"time"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// SkillSnapshot contains the state of one skill at the time of the
+// snapshot. As in the API the XP is stored in tenths of XP.
+type SkillSnapshot struct {
+	ID    runemetrics.SkillID `json:"id"`
+	Level int                 `json:"level"`
+	Rank  int64               `json:"rank"`
+	XP    int64               `json:"xp"`
+}
+
+// Snapshot contains the state of the player at a given time
+type Snapshot struct {
+	Time time.Time `json:"time"`
+
+	CombatLevel      int             `json:"combat_level"`
+	LoggedIn         bool            `json:"logged_in"`
+	QuestsComplete   int             `json:"quests_complete"`
+	QuestsNotStarted int             `json:"quests_not_started"`
+	QuestsStarted    int             `json:"quests_started"`
+	Rank             int64           `json:"rank"`
+	Skills           []SkillSnapshot `json:"skills"`
+	TotalSkill       int64           `json:"total_skill"`
+	TotalXP          int64           `json:"total_xp"`
+}
+
+// NewSnapshot creates a snapshot of the given player info
+func NewSnapshot(p *runemetrics.PlayerInfo, t time.Time) Snapshot {
+	s := Snapshot{
+		Time: t,
+
+		CombatLevel:      p.CombatLevel,
+		LoggedIn:         p.LoggedIn,
+		QuestsComplete:   p.QuestsComplete,
+		QuestsNotStarted: p.QuestsNotStarted,
+		QuestsStarted:    p.QuestsStarted,
+		Rank:             p.NumericRank(),
+		TotalSkill:       p.TotalSkill,
+		TotalXP:          p.TotalXP,
+	}
+
+	for _, sk := range p.SkillValues {
+		s.Skills = append(s.Skills, SkillSnapshot{
+			ID:    sk.ID,
+			Level: sk.Level,
+			Rank:  sk.Rank,
+			XP:    sk.XP,
+		})
+	}
+
+	return s
+}
+
+// Skill returns the state of the given skill or an empty SkillSnapshot
+func (s Snapshot) Skill(id runemetrics.SkillID) SkillSnapshot {
+	for _, sk := range s.Skills {
+		if sk.ID == id {
+			return sk
+		}
+	}
+	return SkillSnapshot{ID: id}
+}
+
+// Equal tells whether both snapshots contain the same data, the time
+// the snapshots were taken is ignored
+func (s Snapshot) Equal(o Snapshot) bool {
+	if s.CombatLevel != o.CombatLevel ||
+		s.LoggedIn != o.LoggedIn ||
+		s.QuestsComplete != o.QuestsComplete ||
+		s.QuestsNotStarted != o.QuestsNotStarted ||
+		s.QuestsStarted != o.QuestsStarted ||
+		s.Rank != o.Rank ||
+		s.TotalSkill != o.TotalSkill ||
+		s.TotalXP != o.TotalXP ||
+		len(s.Skills) != len(o.Skills) {
+		return false
+	}
+
+	for i := range s.Skills {
+		if s.Skills[i] != o.Skills[i] {
+			return false
+		}
+	}
+
+	return true
+}
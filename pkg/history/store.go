@@ -0,0 +1,181 @@
+// Package history persists snapshots of a player's profile into a
+// local bbolt database to keep track of the progress over time
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+var (
+	bucketSnapshots = []byte("snapshots")
+
+	// maxTime is the latest time representable in a key
+	maxTime = time.Unix(0, 1<<63-1)
+)
+
+// Store contains the snapshots of one player
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the history database at the given path
+func Open(dbFile string) (*Store, error) {
+	db, err := bolt.Open(dbFile, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open database")
+	}
+
+	if err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketSnapshots)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "Unable to create bucket")
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error { return s.db.Close() }
+
+// Record stores the snapshot unless it does not differ from the latest
+// snapshot stored. The returned bool indicates whether it was stored.
+func (s *Store) Record(snap Snapshot) (bool, error) {
+	var stored bool
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketSnapshots)
+
+		if _, v := b.Cursor().Last(); v != nil {
+			var last Snapshot
+			if err := json.Unmarshal(v, &last); err != nil {
+				return errors.Wrap(err, "Unable to decode latest snapshot")
+			}
+
+			if last.Equal(snap) {
+				return nil
+			}
+		}
+
+		v, err := json.Marshal(snap)
+		if err != nil {
+			return errors.Wrap(err, "Unable to encode snapshot")
+		}
+
+		stored = true
+		return errors.Wrap(b.Put(timeKey(snap.Time), v), "Unable to store snapshot")
+	})
+
+	return stored, err
+}
+
+// Snapshots returns all snapshots taken within the given time range
+// (inclusive) in chronological order
+func (s *Store) Snapshots(from, to time.Time) ([]Snapshot, error) {
+	var out []Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var (
+			c   = tx.Bucket(bucketSnapshots).Cursor()
+			max = timeKey(to)
+		)
+
+		for k, v := c.Seek(timeKey(from)); k != nil && bytes.Compare(k, max) <= 0; k, v = c.Next() {
+			var snap Snapshot
+			if err := json.Unmarshal(v, &snap); err != nil {
+				return errors.Wrap(err, "Unable to decode snapshot")
+			}
+			out = append(out, snap)
+		}
+
+		return nil
+	})
+
+	return out, err
+}
+
+// At returns the latest snapshot taken at or before the given time or
+// nil if there is none
+func (s *Store) At(t time.Time) (*Snapshot, error) {
+	var out *Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var (
+			c   = tx.Bucket(bucketSnapshots).Cursor()
+			key = timeKey(t)
+		)
+
+		k, v := c.Seek(key)
+		if k == nil || !bytes.Equal(k, key) {
+			// Seek moved to the first key after t (or the end)
+			k, v = c.Prev()
+		}
+
+		if k == nil {
+			return nil
+		}
+
+		out = &Snapshot{}
+		return errors.Wrap(json.Unmarshal(v, out), "Unable to decode snapshot")
+	})
+
+	return out, err
+}
+
+// Latest returns the most recent snapshot or nil if there is none
+func (s *Store) Latest() (*Snapshot, error) {
+	return s.At(maxTime)
+}
+
+// Baseline returns the state of the player at the given time: the
+// latest snapshot taken at or before the given time or, if the history
+// starts after that time, the oldest snapshot available
+func (s *Store) Baseline(t time.Time) (*Snapshot, error) {
+	base, err := s.At(t)
+	if err != nil || base != nil {
+		return base, err
+	}
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		k, v := tx.Bucket(bucketSnapshots).Cursor().Seek(timeKey(t))
+		if k == nil {
+			return nil
+		}
+
+		base = &Snapshot{}
+		return errors.Wrap(json.Unmarshal(v, base), "Unable to decode snapshot")
+	})
+
+	return base, err
+}
+
+// XPGained returns the XP (in tenths) gained in the given skill since
+// the given time based on the stored snapshots
+func (s *Store) XPGained(skill runemetrics.SkillID, since time.Time) (int64, error) {
+	latest, err := s.Latest()
+	if err != nil || latest == nil {
+		return 0, err
+	}
+
+	base, err := s.Baseline(since)
+	if err != nil || base == nil {
+		return 0, err
+	}
+
+	return latest.Skill(skill).XP - base.Skill(skill).XP, nil
+}
+
+func timeKey(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return k
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func testSnapshot(t time.Time, xp int64) Snapshot {
+	return Snapshot{
+		Time:   t,
+		Skills: []SkillSnapshot{{ID: 0, XP: xp}},
+	}
+}
+
+// openTestStore opens a store in a temporary directory, the returned
+// function closes and removes it
+func openTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "runemetrics-history")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %s", err)
+	}
+
+	s, err := Open(path.Join(dir, "history.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Unable to open store: %s", err)
+	}
+
+	return s, func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRecordSkipsUnchanged(t *testing.T) {
+	s, cleanup := openTestStore(t)
+	defer cleanup()
+
+	var (
+		start    = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+		loggedIn = testSnapshot(start.Add(2*time.Minute), 10000)
+	)
+	loggedIn.LoggedIn = true
+
+	for _, tc := range []struct {
+		Name       string
+		Snap       Snapshot
+		WantStored bool
+	}{
+		{Name: "first", Snap: testSnapshot(start, 10000), WantStored: true},
+		{Name: "unchanged", Snap: testSnapshot(start.Add(time.Minute), 10000), WantStored: false},
+		{Name: "logged in", Snap: loggedIn, WantStored: true},
+		{Name: "xp changed", Snap: testSnapshot(start.Add(3*time.Minute), 20000), WantStored: true},
+	} {
+		stored, err := s.Record(tc.Snap)
+		if err != nil {
+			t.Fatalf("Record(%s) failed: %s", tc.Name, err)
+		}
+		if stored != tc.WantStored {
+			t.Errorf("Record(%s) = %v, want %v", tc.Name, stored, tc.WantStored)
+		}
+	}
+
+	snaps, err := s.Snapshots(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Unable to list snapshots: %s", err)
+	}
+
+	var times []time.Time
+	for _, snap := range snaps {
+		times = append(times, snap.Time)
+	}
+
+	want := []time.Time{start, start.Add(2 * time.Minute), start.Add(3 * time.Minute)}
+	if len(times) != len(want) {
+		t.Fatalf("snapshot times = %v, want %v", times, want)
+	}
+	for i := range want {
+		if !times[i].Equal(want[i]) {
+			t.Errorf("snapshot times = %v, want %v", times, want)
+		}
+	}
+}
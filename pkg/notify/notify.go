@@ -0,0 +1,154 @@
+// Package notify delivers notifications about the progress of players
+// to webhooks and local commands
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Kind describes what a notification is about
+type Kind string
+
+// List of notification kinds
+const (
+	KindActivity      Kind = "activity"
+	KindGoalCompleted Kind = "goal_completed"
+	KindLevelUp       Kind = "level_up"
+	KindRank          Kind = "rank"
+)
+
+// Notification is sent to the sinks when a player made progress
+type Notification struct {
+	Kind   Kind      `json:"kind"`
+	Player string    `json:"player"`
+	Text   string    `json:"text"`
+	Time   time.Time `json:"time"`
+
+	// Event contains the type of the activity for activity notifications
+	Event string `json:"event,omitempty"`
+}
+
+// Sink delivers notifications
+type Sink interface {
+	Send(n Notification) error
+}
+
+// Webhook posts the notification as JSON to the URL
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// Send implements Sink
+func (w Webhook) Send(n Notification) error {
+	return postJSON(w.HTTPClient, w.URL, n)
+}
+
+// Discord posts the notification as a Discord webhook message
+type Discord struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// Send implements Sink
+func (d Discord) Send(n Notification) error {
+	return postJSON(d.HTTPClient, d.URL, map[string]string{"content": n.Text})
+}
+
+// Slack posts the notification as a Slack incoming webhook message
+type Slack struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// Send implements Sink
+func (s Slack) Send(n Notification) error {
+	return postJSON(s.HTTPClient, s.URL, map[string]string{"text": n.Text})
+}
+
+// Command executes the command through the shell for every notification.
+// The notification is passed as JSON on stdin and in the environment
+// variables RUNEMETRICS_KIND, RUNEMETRICS_EVENT, RUNEMETRICS_PLAYER and
+// RUNEMETRICS_TEXT.
+type Command struct {
+	Command string
+}
+
+// Send implements Sink
+func (c Command) Send(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode notification")
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", c.Command)
+	cmd.Env = append(os.Environ(),
+		"RUNEMETRICS_KIND="+string(n.Kind),
+		"RUNEMETRICS_EVENT="+n.Event,
+		"RUNEMETRICS_PLAYER="+n.Player,
+		"RUNEMETRICS_TEXT="+n.Text,
+	)
+	cmd.Stdin = bytes.NewReader(body)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "Notification command failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// ParseSink creates a webhook sink from a specification in the form
+// <type>:<url> with type being one of webhook, discord or slack
+func ParseSink(spec string, hc *http.Client) (Sink, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errors.Errorf("Invalid notification sink %q, expected <type>:<url>", spec)
+	}
+
+	switch parts[0] {
+
+	case "discord":
+		return Discord{URL: parts[1], HTTPClient: hc}, nil
+
+	case "slack":
+		return Slack{URL: parts[1], HTTPClient: hc}, nil
+
+	case "webhook":
+		return Webhook{URL: parts[1], HTTPClient: hc}, nil
+
+	default:
+		return nil, errors.Errorf("Unknown notification sink type %q", parts[0])
+
+	}
+}
+
+func postJSON(hc *http.Client, uri string, v interface{}) error {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	body, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "Unable to encode notification")
+	}
+
+	resp, err := hc.Post(uri, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "Unable to send notification")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("Webhook answered with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
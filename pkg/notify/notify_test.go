@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSinkPayloads(t *testing.T) {
+	n := Notification{
+		Kind:   KindLevelUp,
+		Player: "Tester",
+		Text:   "Tester reached level 99 in Attack",
+		Time:   time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	for _, tc := range []struct {
+		Name string
+		Sink func(url string) Sink
+		Want map[string]string
+	}{
+		{
+			Name: "webhook",
+			Sink: func(url string) Sink { return Webhook{URL: url} },
+			Want: map[string]string{
+				"kind":   "level_up",
+				"player": "Tester",
+				"text":   n.Text,
+				"time":   "2020-05-01T12:00:00Z",
+			},
+		},
+		{
+			Name: "discord",
+			Sink: func(url string) Sink { return Discord{URL: url} },
+			Want: map[string]string{"content": n.Text},
+		},
+		{
+			Name: "slack",
+			Sink: func(url string) Sink { return Slack{URL: url} },
+			Want: map[string]string{"text": n.Text},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			var got map[string]string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q, want application/json", ct)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("Unable to decode payload: %s", err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			if err := tc.Sink(srv.URL).Send(n); err != nil {
+				t.Fatalf("Send() = %v", err)
+			}
+
+			if len(got) != len(tc.Want) {
+				t.Errorf("payload = %v, want %v", got, tc.Want)
+			}
+			for k, v := range tc.Want {
+				if got[k] != v {
+					t.Errorf("payload[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestSinkErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	if err := (Webhook{URL: srv.URL}).Send(Notification{Kind: KindActivity}); err == nil {
+		t.Error("Send() succeeded on status 400, want error")
+	}
+}
+
+func TestParseSink(t *testing.T) {
+	for _, tc := range []struct {
+		Spec    string
+		Want    Sink
+		WantErr bool
+	}{
+		{Spec: "discord:https://example.com/a", Want: Discord{URL: "https://example.com/a"}},
+		{Spec: "slack:https://example.com/b", Want: Slack{URL: "https://example.com/b"}},
+		{Spec: "webhook:https://example.com/c", Want: Webhook{URL: "https://example.com/c"}},
+		{Spec: "webhook:", WantErr: true},
+		{Spec: "https://example.com", WantErr: true},
+		{Spec: "mail:someone@example.com", WantErr: true},
+	} {
+		t.Run(tc.Spec, func(t *testing.T) {
+			s, err := ParseSink(tc.Spec, nil)
+			if tc.WantErr {
+				if err == nil {
+					t.Errorf("ParseSink(%q) = %#v, want error", tc.Spec, s)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseSink(%q) failed: %s", tc.Spec, err)
+			}
+			if s != tc.Want {
+				t.Errorf("ParseSink(%q) = %#v, want %#v", tc.Spec, s, tc.Want)
+			}
+		})
+	}
+}
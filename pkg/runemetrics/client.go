@@ -0,0 +1,89 @@
+// Package runemetrics contains a client for the RuneMetrics profile API
+// together with the static skill information and level math to work
+// with the returned data
+package runemetrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBaseURL points to the official RuneMetrics API
+const DefaultBaseURL = "https://apps.runescape.com/runemetrics"
+
+// Client fetches player information from the RuneMetrics API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Option configures a Client created through New
+type Option func(*Client)
+
+// WithBaseURL replaces the DefaultBaseURL (i.e. to use a stand-in server)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.BaseURL = strings.TrimRight(baseURL, "/") }
+}
+
+// WithHTTPClient replaces the http.DefaultClient used to execute requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.HTTPClient = hc }
+}
+
+// New creates a new Client with the given options applied
+func New(opts ...Option) *Client {
+	c := &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: http.DefaultClient,
+	}
+
+	for _, o := range opts {
+		o(c)
+	}
+
+	return c
+}
+
+// GetPlayerInfo fetches the profile of the given player including
+// the given number of activities
+func (c Client) GetPlayerInfo(name string, activities int) (*PlayerInfo, error) {
+	if name == "" {
+		return nil, errors.New("Player name must not be empty")
+	}
+
+	params := url.Values{
+		"user":       []string{name},
+		"activities": []string{strconv.Itoa(activities)},
+	}
+	uri := c.BaseURL + "/profile/profile?" + params.Encode()
+
+	resp, err := c.HTTPClient.Get(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to query profile data")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errorFromStatus(resp.StatusCode)
+	}
+
+	// Errors are reported with status 200 and an error field
+	var out struct {
+		Error string `json:"error"`
+		PlayerInfo
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode profile data")
+	}
+
+	if out.Error != "" {
+		return nil, errorFromCode(out.Error)
+	}
+
+	return &out.PlayerInfo, nil
+}
@@ -0,0 +1,40 @@
+package runemetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/runemetrics/profile/profile" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if user := r.URL.Query().Get("user"); user != "Zezima" {
+			t.Errorf("user = %q, want Zezima", user)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"Zezima","totalxp":1154,"skillvalues":[]}`))
+	}))
+	defer srv.Close()
+
+	for _, base := range []string{
+		srv.URL + "/runemetrics",
+		srv.URL + "/runemetrics/",
+		srv.URL + "/runemetrics//",
+	} {
+		p, err := New(WithBaseURL(base)).GetPlayerInfo("Zezima", 0)
+		if err != nil {
+			t.Errorf("GetPlayerInfo with base URL %q failed: %s", base, err)
+			continue
+		}
+
+		if p.Name != "Zezima" || p.TotalXP != 1154 {
+			t.Errorf("GetPlayerInfo with base URL %q = %+v", base, p)
+		}
+	}
+}
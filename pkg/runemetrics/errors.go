@@ -0,0 +1,65 @@
+package runemetrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Errors returned by the Client when the API refused to deliver the
+// profile. Use errors.Cause to compare them.
+var (
+	ErrProfilePrivate = errors.New("Profile is private")
+	ErrNotAMember     = errors.New("Player is not a member")
+	ErrNoProfile      = errors.New("Profile does not exist")
+	ErrRateLimited    = errors.New("Rate limit exceeded")
+	ErrServerError    = errors.New("RuneMetrics server error")
+)
+
+// APIError is returned for error payloads not mapped to one of the
+// known errors above
+type APIError struct {
+	Code string
+}
+
+func (a APIError) Error() string {
+	return fmt.Sprintf("RuneMetrics API returned error %q", a.Code)
+}
+
+func errorFromCode(code string) error {
+	switch code {
+	case "PROFILE_PRIVATE":
+		return ErrProfilePrivate
+	case "NOT_A_MEMBER":
+		return ErrNotAMember
+	case "NO_PROFILE":
+		return ErrNoProfile
+	default:
+		return APIError{Code: code}
+	}
+}
+
+func errorFromStatus(status int) error {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrServerError
+	default:
+		return errors.Errorf("Unexpected HTTP status %d", status)
+	}
+}
+
+// IsTemporary tells whether the error might go away when retrying the
+// request later (network issues, rate limits, server errors)
+func IsTemporary(err error) bool {
+	switch cause := errors.Cause(err); cause {
+	case nil, ErrProfilePrivate, ErrNotAMember, ErrNoProfile:
+		return false
+
+	default:
+		_, isAPIError := cause.(APIError)
+		return !isAPIError
+	}
+}
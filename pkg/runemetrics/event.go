@@ -0,0 +1,164 @@
+package runemetrics
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EventType classifies an entry of the adventurer's log
+type EventType string
+
+// Known event types, entries not matching any of them are EventOther
+const (
+	EventLevelUp       EventType = "level_up"
+	EventXPMilestone   EventType = "xp_milestone"
+	EventQuest         EventType = "quest"
+	EventBossKill      EventType = "boss_kill"
+	EventDrop          EventType = "drop"
+	EventClueScroll    EventType = "clue_scroll"
+	EventTreasureTrail EventType = "treasure_trail"
+	EventAchievement   EventType = "achievement"
+	EventOther         EventType = "other"
+)
+
+// EventTypes contains all event types in the order to cycle through them
+var EventTypes = []EventType{
+	EventLevelUp, EventXPMilestone, EventQuest, EventBossKill, EventDrop,
+	EventClueScroll, EventTreasureTrail, EventAchievement, EventOther,
+}
+
+// Label returns a human readable name of the event type
+func (e EventType) Label() string {
+	switch e {
+	case EventLevelUp:
+		return "Level-up"
+	case EventXPMilestone:
+		return "XP milestone"
+	case EventQuest:
+		return "Quest"
+	case EventBossKill:
+		return "Boss kill"
+	case EventDrop:
+		return "Drop"
+	case EventClueScroll:
+		return "Clue scroll"
+	case EventTreasureTrail:
+		return "Treasure trail"
+	case EventAchievement:
+		return "Achievement"
+	default:
+		return "Other"
+	}
+}
+
+// Event is an activity classified by its type with the information
+// extracted from its text. Only the fields belonging to the type are set.
+type Event struct {
+	Type EventType
+
+	// Skill and Level are set for level-ups, Skill and XP for XP milestones
+	Skill SkillID
+	Level int
+	XP    int64
+
+	// Name contains the quest, boss or achievement name
+	Name string
+	// Count contains the number of bosses killed
+	Count int
+	// Item contains the item dropped or received from a treasure trail
+	Item string
+	// Difficulty contains the tier of clue scrolls and treasure trails
+	Difficulty string
+}
+
+var (
+	eventAchievement   = regexp.MustCompile(`(?i)^Achievement: (.+?)\.?$`)
+	eventBossKill      = regexp.MustCompile(`(?i)^I killed (an?|\d+) (.+?)\.?$`)
+	eventBossName      = regexp.MustCompile(`(?i)boss monsters called: (.+?)\.?$`)
+	eventClueScroll    = regexp.MustCompile(`(?i)clue scroll`)
+	eventDifficulty    = regexp.MustCompile(`(?i)\b(easy|medium|hard|elite|master)\b`)
+	eventDrop          = regexp.MustCompile(`(?i)^I found (?:an? |some )?(.+?)\.?$`)
+	eventLevelUp       = regexp.MustCompile(`(?i)^I levelled my (.+) skill, I am now level (\d+)`)
+	eventQuest         = regexp.MustCompile(`(?i)^Quest complete: (.+?)\.?$`)
+	eventTreasureTrail = regexp.MustCompile(`(?i)completed an? (\w+) treasure trail`)
+	eventTrailReward   = regexp.MustCompile(`(?i)I got (?:an? |some )?(.+?) out of it`)
+	eventXPMilestone   = regexp.MustCompile(`(?i)^(\d+)XP in (.+?)\.?$`)
+)
+
+// Event classifies the activity and extracts the information contained
+// in its text and details
+func (a Activity) Event() Event {
+	var (
+		text    = strings.TrimSpace(a.Text)
+		details = strings.Replace(strings.TrimSpace(a.Details), "  ", " ", -1)
+	)
+
+	if m := eventLevelUp.FindStringSubmatch(details); m != nil {
+		if id, ok := skillByName(m[1]); ok {
+			level, _ := strconv.Atoi(m[2])
+			return Event{Type: EventLevelUp, Skill: id, Level: level}
+		}
+	}
+
+	if m := eventXPMilestone.FindStringSubmatch(text); m != nil {
+		if id, ok := skillByName(m[2]); ok {
+			xp, _ := strconv.ParseInt(m[1], 10, 64)
+			return Event{Type: EventXPMilestone, Skill: id, XP: xp}
+		}
+	}
+
+	if m := eventQuest.FindStringSubmatch(text); m != nil {
+		return Event{Type: EventQuest, Name: m[1]}
+	}
+
+	if m := eventAchievement.FindStringSubmatch(text); m != nil {
+		return Event{Type: EventAchievement, Name: m[1]}
+	}
+
+	if m := eventBossKill.FindStringSubmatch(text); m != nil {
+		count, err := strconv.Atoi(m[1])
+		if err != nil {
+			// Single kills are reported as "a" / "an"
+			count = 1
+		}
+
+		name := m[2]
+		if bm := eventBossName.FindStringSubmatch(details); bm != nil {
+			name = bm[1]
+		}
+
+		return Event{Type: EventBossKill, Name: name, Count: count}
+	}
+
+	if m := eventTreasureTrail.FindStringSubmatch(details); m != nil {
+		ev := Event{Type: EventTreasureTrail, Difficulty: strings.ToLower(m[1])}
+		if rm := eventTrailReward.FindStringSubmatch(details); rm != nil {
+			ev.Item = rm[1]
+		}
+		return ev
+	}
+
+	if eventClueScroll.MatchString(text) || eventClueScroll.MatchString(details) {
+		ev := Event{Type: EventClueScroll}
+		if m := eventDifficulty.FindStringSubmatch(text + " " + details); m != nil {
+			ev.Difficulty = strings.ToLower(m[1])
+		}
+		return ev
+	}
+
+	if m := eventDrop.FindStringSubmatch(text); m != nil {
+		return Event{Type: EventDrop, Item: m[1]}
+	}
+
+	return Event{Type: EventOther}
+}
+
+func skillByName(name string) (SkillID, bool) {
+	for _, s := range SkillList {
+		if strings.EqualFold(s.Name, name) {
+			return SkillID(s.ID), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,104 @@
+package runemetrics
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestActivityEvent(t *testing.T) {
+	for _, tc := range []struct {
+		Name     string
+		Activity Activity
+		Want     Event
+	}{
+		// Entries taken from cmd/fake-runemetrics/fixtures/zezima.json
+		{
+			Name:     "level-up",
+			Activity: Activity{Text: "Levelled Up Slayer.", Details: "I levelled my Slayer skill, I am now level 96."},
+			Want:     Event{Type: EventLevelUp, Skill: 18, Level: 96},
+		},
+		{
+			Name:     "boss kill",
+			Activity: Activity{Text: "I killed 5 Vorago.", Details: "I killed 5 Vorago."},
+			Want:     Event{Type: EventBossKill, Name: "Vorago", Count: 5},
+		},
+		{
+			Name:     "boss kill with boss name in details",
+			Activity: Activity{Text: "I killed 10 Nex.", Details: "I killed 10 boss monsters called: Nex."},
+			Want:     Event{Type: EventBossKill, Name: "Nex", Count: 10},
+		},
+		{
+			Name:     "drop",
+			Activity: Activity{Text: "I found a Tectonic energy", Details: "I found a Tectonic energy after killing a Vorago."},
+			Want:     Event{Type: EventDrop, Item: "Tectonic energy"},
+		},
+		{
+			Name:     "quest",
+			Activity: Activity{Text: "Quest complete: Desperate Measures", Details: "I have completed the quest: Desperate Measures."},
+			Want:     Event{Type: EventQuest, Name: "Desperate Measures"},
+		},
+		{
+			Name:     "treasure trail",
+			Activity: Activity{Text: "I found a Third age ranger body", Details: "I have completed an elite treasure trail. I got a Third age ranger body out of it."},
+			Want:     Event{Type: EventTreasureTrail, Difficulty: "elite", Item: "Third age ranger body"},
+		},
+		{
+			Name:     "XP milestone",
+			Activity: Activity{Text: "13000000XP in Fishing", Details: "I now have at least 13000000 experience points in the Fishing skill."},
+			Want:     Event{Type: EventXPMilestone, Skill: 10, XP: 13000000},
+		},
+		{
+			Name:     "achievement",
+			Activity: Activity{Text: "Achievement: Master of All", Details: "I completed the achievement: Master of All."},
+			Want:     Event{Type: EventAchievement, Name: "Master of All"},
+		},
+
+		// Entries not contained in the fixtures
+		{
+			Name:     "single boss kill",
+			Activity: Activity{Text: "I killed a Kalphite King.", Details: "I killed a Kalphite King."},
+			Want:     Event{Type: EventBossKill, Name: "Kalphite King", Count: 1},
+		},
+		{
+			Name:     "clue scroll",
+			Activity: Activity{Text: "I found a clue scroll (hard)", Details: "I found a hard clue scroll after killing a Black dragon."},
+			Want:     Event{Type: EventClueScroll, Difficulty: "hard"},
+		},
+		{
+			Name:     "level-up of unknown skill",
+			Activity: Activity{Text: "Levelled Up Sailing.", Details: "I levelled my Sailing skill, I am now level 10."},
+			Want:     Event{Type: EventOther},
+		},
+		{
+			Name:     "unmatched",
+			Activity: Activity{Text: "Visited the Grand Exchange", Details: "I visited the Grand Exchange."},
+			Want:     Event{Type: EventOther},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := tc.Activity.Event(); !reflect.DeepEqual(got, tc.Want) {
+				t.Errorf("Event() = %+v, want %+v", got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestFixtureActivitiesAreClassified(t *testing.T) {
+	raw, err := ioutil.ReadFile("../../cmd/fake-runemetrics/fixtures/zezima.json")
+	if err != nil {
+		t.Fatalf("Unable to read fixture: %s", err)
+	}
+
+	var p PlayerInfo
+	if err = json.Unmarshal(raw, &p); err != nil {
+		t.Fatalf("Unable to decode fixture: %s", err)
+	}
+
+	for _, a := range p.Activities {
+		if ev := a.Event(); ev.Type == EventOther {
+			t.Errorf("Activity %q was not classified", a.Details)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package runemetrics
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+// officialNormalLevels is the XP table published for the normal skill
+// curve the generated curve must match
+var officialNormalLevels = map[int]int64{
+	1:   0,
+	2:   83,
+	3:   174,
+	4:   276,
+	5:   388,
+	6:   512,
+	7:   650,
+	8:   801,
+	9:   969,
+	10:  1154,
+	11:  1358,
+	12:  1584,
+	13:  1833,
+	14:  2107,
+	15:  2411,
+	16:  2746,
+	17:  3115,
+	18:  3523,
+	19:  3973,
+	20:  4470,
+	21:  5018,
+	22:  5624,
+	23:  6291,
+	24:  7028,
+	25:  7842,
+	26:  8740,
+	27:  9730,
+	28:  10824,
+	29:  12031,
+	30:  13363,
+	31:  14833,
+	32:  16456,
+	33:  18247,
+	34:  20224,
+	35:  22406,
+	36:  24815,
+	37:  27473,
+	38:  30408,
+	39:  33648,
+	40:  37224,
+	41:  41171,
+	42:  45529,
+	43:  50339,
+	44:  55649,
+	45:  61512,
+	46:  67983,
+	47:  75127,
+	48:  83014,
+	49:  91721,
+	50:  101333,
+	51:  111945,
+	52:  123660,
+	53:  136594,
+	54:  150872,
+	55:  166636,
+	56:  184040,
+	57:  203254,
+	58:  224466,
+	59:  247886,
+	60:  273742,
+	61:  302288,
+	62:  333804,
+	63:  368599,
+	64:  407015,
+	65:  449428,
+	66:  496254,
+	67:  547953,
+	68:  605032,
+	69:  668051,
+	70:  737627,
+	71:  814445,
+	72:  899257,
+	73:  992895,
+	74:  1096278,
+	75:  1210421,
+	76:  1336443,
+	77:  1475581,
+	78:  1629200,
+	79:  1798808,
+	80:  1986068,
+	81:  2192818,
+	82:  2421087,
+	83:  2673114,
+	84:  2951373,
+	85:  3258594,
+	86:  3597792,
+	87:  3972294,
+	88:  4385776,
+	89:  4842295,
+	90:  5346332,
+	91:  5902831,
+	92:  6517253,
+	93:  7195629,
+	94:  7944614,
+	95:  8771558,
+	96:  9684577,
+	97:  10692629,
+	98:  11805606,
+	99:  13034431,
+	100: 14391160,
+	101: 15889109,
+	102: 17542976,
+	103: 19368992,
+	104: 21385073,
+	105: 23611006,
+	106: 26068632,
+	107: 28782069,
+	108: 31777943,
+	109: 35085654,
+	110: 38737661,
+	111: 42769801,
+	112: 47221641,
+	113: 52136869,
+	114: 57563718,
+	115: 63555443,
+	116: 70170840,
+	117: 77474828,
+	118: 85539082,
+	119: 94442737,
+	120: 104273167,
+	121: 115126838,
+	122: 127110260,
+	123: 140341028,
+	124: 154948977,
+	125: 171077457,
+	126: 188884740,
+	127: MaxXP,
+}
+
+func TestNormalCurveMatchesOfficialTable(t *testing.T) {
+	if len(levels) != len(officialNormalLevels) {
+		t.Fatalf("Generated curve has %d entries, official table %d", len(levels), len(officialNormalLevels))
+	}
+
+	for level, want := range officialNormalLevels {
+		if got := levels[level]; got != want {
+			t.Errorf("Level %d requires %d XP, official table says %d", level, got, want)
+		}
+	}
+}
+
+func TestMasterCurve(t *testing.T) {
+	// The elite curve is kept as a table as there is no published
+	// formula, pin the published values at well-known levels
+	for level, want := range map[int]int64{
+		1:   0,
+		2:   830,
+		50:  2100917,
+		99:  36073511,
+		120: 80618654,
+		150: 194927409,
+		151: MaxXP,
+	} {
+		if got := masterLevels[level]; got != want {
+			t.Errorf("Level %d requires %d XP, want %d", level, got, want)
+		}
+	}
+
+	for level := 2; level <= len(masterLevels); level++ {
+		if masterLevels[level] <= masterLevels[level-1] {
+			t.Errorf("Level %d requires %d XP, not more than level %d (%d XP)",
+				level, masterLevels[level], level-1, masterLevels[level-1])
+		}
+	}
+}
+
+func TestLevelXPOutOfRange(t *testing.T) {
+	for _, s := range testSkills() {
+		for _, level := range []int{-1, 0, 1} {
+			if got := s.LevelXP(level); got != 0 {
+				t.Errorf("%s: LevelXP(%d) = %d, want 0", s.Name, level, got)
+			}
+		}
+
+		for _, level := range []int{len(s.levelTree()), len(s.levelTree()) + 1, 1000} {
+			if got := s.LevelXP(level); got != MaxXP {
+				t.Errorf("%s: LevelXP(%d) = %d, want %d", s.Name, level, got, MaxXP)
+			}
+			if got := s.XPToTargetLevel(level, 0); got != MaxXP {
+				t.Errorf("%s: XPToTargetLevel(%d, 0) = %d, want %d", s.Name, level, got, MaxXP)
+			}
+		}
+	}
+}
+
+// testSkills returns every known skill with and without virtual levels
+func testSkills() []SkillInfo {
+	var out []SkillInfo
+	for _, s := range SkillList {
+		out = append(out, s, s.Virtual())
+	}
+	return out
+}
+
+func TestLevelXPRoundTrip(t *testing.T) {
+	for _, s := range testSkills() {
+		for level := 1; level <= s.Cap(); level++ {
+			xp := s.LevelXP(level)
+
+			if got := s.LevelFromXP(xp); got != level {
+				t.Errorf("%s (cap %d): LevelFromXP(LevelXP(%d)) = %d", s.Name, s.Cap(), level, got)
+			}
+
+			if level > 1 {
+				if got := s.LevelFromXP(xp - 1); got != level-1 {
+					t.Errorf("%s (cap %d): LevelFromXP(LevelXP(%d) - 1) = %d", s.Name, s.Cap(), level, got)
+				}
+			}
+
+			if got := s.XPToTargetLevel(level, xp); got != 0 {
+				t.Errorf("%s (cap %d): XPToTargetLevel(%d, LevelXP(%d)) = %d", s.Name, s.Cap(), level, level, got)
+			}
+		}
+	}
+}
+
+func TestLevelProperties(t *testing.T) {
+	for _, s := range testSkills() {
+		s := s
+
+		property := func(raw uint32) bool {
+			var (
+				xp    = int64(raw) % (MaxXP + 1)
+				level = s.LevelFromXP(xp)
+			)
+
+			if level < 1 || level > s.Cap() {
+				t.Logf("%s (cap %d): LevelFromXP(%d) = %d out of range", s.Name, s.Cap(), xp, level)
+				return false
+			}
+
+			if s.LevelFromXP(xp+1) < level {
+				t.Logf("%s (cap %d): LevelFromXP decreases after %d XP", s.Name, s.Cap(), xp)
+				return false
+			}
+
+			if s.LevelXP(level) > xp {
+				t.Logf("%s (cap %d): level %d requires more than %d XP", s.Name, s.Cap(), level, xp)
+				return false
+			}
+
+			if pct := s.LevelPercentage(xp); pct < 0 || pct > 100 {
+				t.Logf("%s (cap %d): LevelPercentage(%d) = %f", s.Name, s.Cap(), xp, pct)
+				return false
+			}
+
+			if got, want := s.XPToTargetLevel(s.Cap(), xp), s.LevelXP(s.Cap())-xp; got != want {
+				t.Logf("%s (cap %d): XPToTargetLevel(%d, %d) = %d, want %d", s.Name, s.Cap(), s.Cap(), xp, got, want)
+				return false
+			}
+
+			next := s.XPToNextLevel(xp)
+			if s.IsMaxed(xp) {
+				if next != 0 || s.LevelPercentage(xp) != 100 {
+					t.Logf("%s (cap %d): maxed at %d XP but %d XP to next level", s.Name, s.Cap(), xp, next)
+					return false
+				}
+				return true
+			}
+
+			if next <= 0 || s.LevelFromXP(xp+next) != level+1 || s.LevelFromXP(xp+next-1) != level {
+				t.Logf("%s (cap %d): XPToNextLevel(%d) = %d does not lead to level %d", s.Name, s.Cap(), xp, next, level+1)
+				return false
+			}
+
+			return true
+		}
+
+		if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+			t.Errorf("%s (cap %d): %s", s.Name, s.Cap(), err)
+		}
+	}
+}
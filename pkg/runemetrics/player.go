@@ -0,0 +1,130 @@
+package runemetrics
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Activity represents one entry of the adventurer's log
+type Activity struct {
+	Date    string `json:"date"`
+	Details string `json:"details"`
+	Text    string `json:"text"`
+}
+
+// GetParsedDate parses the date of the activity (given in London time)
+func (a Activity) GetParsedDate() (time.Time, error) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "Unable to load London time information")
+	}
+
+	return time.ParseInLocation("02-Jan-2006 15:04", a.Date, loc)
+}
+
+// Skill contains the current state of one skill of the player
+type Skill struct {
+	ID    SkillID `json:"id"`
+	Level int     `json:"level"`
+	Rank  int64   `json:"rank"`
+	XP    int64   `json:"xp"`
+
+	Updated time.Time
+}
+
+// PlayerInfo represents the profile of a player as returned by RuneMetrics
+type PlayerInfo struct {
+	Activities       []Activity `json:"activities"`
+	CombatLevel      int        `json:"combatlevel"`
+	LoggedIn         bool       `json:"loggedIn,string"`
+	Magic            int64      `json:"magic"`
+	Melee            int64      `json:"melee"`
+	Name             string     `json:"name"`
+	QuestsComplete   int        `json:"questscomplete"`
+	QuestsNotStarted int        `json:"questsnotstarted"`
+	QuestsStarted    int        `json:"questsstarted"`
+	Ranged           int64      `json:"ranged"`
+	Rank             string     `json:"rank"`
+	SkillValues      []Skill    `json:"skillvalues"`
+	TotalSkill       int64      `json:"totalskill"`
+	TotalXP          int64      `json:"totalxp"`
+}
+
+// NumericRank returns the overall rank as a number
+func (p PlayerInfo) NumericRank() int64 {
+	v, _ := strconv.ParseInt(strings.Replace(p.Rank, ",", "", -1), 10, 64)
+	return v
+}
+
+// GetSkill returns the skill with the given ID or an empty Skill
+func (p PlayerInfo) GetSkill(s SkillID) Skill {
+	for _, sk := range p.SkillValues {
+		if sk.ID == s {
+			return sk
+		}
+	}
+	return Skill{}
+}
+
+// MergeHistory carries over update times and older
+// activities from a previously fetched profile of the same player
+func (p *PlayerInfo) MergeHistory(prev *PlayerInfo) {
+	if prev == nil {
+		return
+	}
+
+	for i, nSk := range p.SkillValues {
+		oSk := prev.GetSkill(nSk.ID)
+
+		if oSk.XP == nSk.XP {
+			p.SkillValues[i].Updated = oSk.Updated
+			continue
+		}
+
+		p.SkillValues[i].Updated = time.Now()
+	}
+
+	if len(p.Activities) == 0 {
+		// Feed is empty (new account or feed reset), there is nothing
+		// to anchor the merge on: keep the history we already know about
+		p.Activities = append(p.Activities, prev.Activities...)
+		return
+	}
+
+	var (
+		lastActivity = p.Activities[len(p.Activities)-1]
+		skip         = true
+	)
+
+	for _, a := range prev.Activities {
+		// Times are no good match: they might be duplicated, we search
+		// last message which should never duplicate.
+		if a.Details == lastActivity.Details {
+			skip = false
+			continue
+		}
+
+		if skip {
+			continue
+		}
+
+		p.Activities = append(p.Activities, a)
+	}
+}
+
+// NormalizePlayerName returns a form of the player name suitable as a
+// key: names are case insensitive and spaces, hyphens and underscores
+// are treated as the same character
+func NormalizePlayerName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '-', '_':
+			return '_'
+		default:
+			return r
+		}
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
@@ -0,0 +1,88 @@
+package runemetrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeHistory(t *testing.T) {
+	var (
+		older   = Activity{Date: "01-Oct-2026 10:00", Details: "older", Text: "older"}
+		anchor  = Activity{Date: "02-Oct-2026 10:00", Details: "anchor", Text: "anchor"}
+		newer   = Activity{Date: "03-Oct-2026 10:00", Details: "newer", Text: "newer"}
+		newest  = Activity{Date: "04-Oct-2026 10:00", Details: "newest", Text: "newest"}
+		missing = Activity{Date: "05-Oct-2026 10:00", Details: "missing", Text: "missing"}
+
+		updated = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	)
+
+	for _, tc := range []struct {
+		Name       string
+		Prev       *PlayerInfo
+		Activities []Activity
+		Want       []Activity
+	}{
+		{
+			Name:       "nil prev",
+			Prev:       nil,
+			Activities: []Activity{newer, anchor},
+			Want:       []Activity{newer, anchor},
+		},
+		{
+			Name:       "empty new feed",
+			Prev:       &PlayerInfo{Activities: []Activity{anchor, older}},
+			Activities: nil,
+			Want:       []Activity{anchor, older},
+		},
+		{
+			Name:       "empty prev feed",
+			Prev:       &PlayerInfo{},
+			Activities: []Activity{newer, anchor},
+			Want:       []Activity{newer, anchor},
+		},
+		{
+			Name:       "anchor found in prev",
+			Prev:       &PlayerInfo{Activities: []Activity{newer, anchor, older}},
+			Activities: []Activity{newest, newer, anchor},
+			Want:       []Activity{newest, newer, anchor, older},
+		},
+		{
+			Name:       "anchor missing from prev",
+			Prev:       &PlayerInfo{Activities: []Activity{anchor, older}},
+			Activities: []Activity{newest, missing},
+			Want:       []Activity{newest, missing},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			p := &PlayerInfo{Activities: tc.Activities}
+			p.MergeHistory(tc.Prev)
+
+			if !reflect.DeepEqual(p.Activities, tc.Want) {
+				t.Errorf("Activities = %v, want %v", p.Activities, tc.Want)
+			}
+		})
+	}
+
+	t.Run("update times", func(t *testing.T) {
+		var (
+			prev = &PlayerInfo{SkillValues: []Skill{
+				{ID: 0, XP: 100, Updated: updated},
+				{ID: 1, XP: 100, Updated: updated},
+			}}
+			p = &PlayerInfo{SkillValues: []Skill{
+				{ID: 0, XP: 100},
+				{ID: 1, XP: 200},
+			}}
+		)
+
+		p.MergeHistory(prev)
+
+		if !p.SkillValues[0].Updated.Equal(updated) {
+			t.Errorf("Unchanged skill has update time %s, want %s", p.SkillValues[0].Updated, updated)
+		}
+		if !p.SkillValues[1].Updated.After(updated) {
+			t.Errorf("Changed skill kept update time %s", p.SkillValues[1].Updated)
+		}
+	})
+}
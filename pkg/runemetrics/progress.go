@@ -0,0 +1,50 @@
+package runemetrics
+
+// ProgressMode defines the reference point the progress towards a
+// target level is measured from
+type ProgressMode string
+
+// Known progress modes, an empty mode is treated as ProgressAbsolute
+const (
+	// ProgressAbsolute measures the progress from 0 XP
+	ProgressAbsolute ProgressMode = "absolute"
+	// ProgressFromStart measures the progress from the XP the skill had
+	// when the target was set
+	ProgressFromStart ProgressMode = "start"
+	// ProgressFromLevel measures the progress from the current level
+	ProgressFromLevel ProgressMode = "level"
+)
+
+var progressModes = []ProgressMode{ProgressAbsolute, ProgressFromStart, ProgressFromLevel}
+
+// IsValid tells whether the mode is one of the known progress modes
+func (p ProgressMode) IsValid() bool {
+	for _, m := range progressModes {
+		if p == m {
+			return true
+		}
+	}
+	return p == ""
+}
+
+// Next returns the next progress mode to cycle through them
+func (p ProgressMode) Next() ProgressMode {
+	for i, m := range progressModes {
+		if p == m {
+			return progressModes[(i+1)%len(progressModes)]
+		}
+	}
+	return progressModes[1]
+}
+
+// Label returns a human readable description of the mode
+func (p ProgressMode) Label() string {
+	switch p {
+	case ProgressFromStart:
+		return "since target was set"
+	case ProgressFromLevel:
+		return "from current level"
+	default:
+		return "from 0 XP"
+	}
+}
@@ -0,0 +1,264 @@
+package runemetrics
+
+// DefaultMaxLevel is the level cap of skills not defining a MaxLevel
+const DefaultMaxLevel = 99
+
+// SkillInfo contains the static information about a skill
+type SkillInfo struct {
+	ID       uint
+	Name     string
+	Color    string
+	MaxLevel int
+	Elite    bool // Skill uses the elite XP curve instead of the normal one
+}
+
+// Cap returns the maximum level obtainable in the skill
+func (s SkillInfo) Cap() int {
+	if s.MaxLevel > 0 {
+		return s.MaxLevel
+	}
+	return DefaultMaxLevel
+}
+
+// Virtual returns a copy of the SkillInfo with the level cap raised to
+// the highest virtual level reachable within the XP limit
+func (s SkillInfo) Virtual() SkillInfo {
+	// Last entry of the level tree is the XP limit, not a level
+	s.MaxLevel = len(s.levelTree()) - 1
+	return s
+}
+
+// IsMaxed tells whether the given XP reaches the level cap
+func (s SkillInfo) IsMaxed(xp int64) bool {
+	return s.LevelFromXP(xp) >= s.Cap()
+}
+
+// LevelFromXP calculates the level reached with the given XP
+func (s SkillInfo) LevelFromXP(xp int64) int {
+	levelTree := s.levelTree()
+
+	for i := 1; i <= len(levelTree); i++ {
+		if levelTree[i] > xp {
+			return minInt(i-1, s.Cap())
+		}
+	}
+
+	// XP limit reached
+	return minInt(len(levelTree)-1, s.Cap())
+}
+
+// LevelXP returns the XP required to reach the given level, levels
+// beyond the XP limit require MaxXP
+func (s SkillInfo) LevelXP(level int) int64 {
+	levelTree := s.levelTree()
+
+	switch {
+	case level <= 1:
+		return 0
+	case level >= len(levelTree):
+		return MaxXP
+	default:
+		return levelTree[level]
+	}
+}
+
+// LevelPercentage returns the progress towards the next level
+func (s SkillInfo) LevelPercentage(xp int64) float64 {
+	if s.IsMaxed(xp) {
+		return 100
+	}
+
+	var (
+		level  = s.LevelFromXP(xp)
+		xpCurr = float64(s.LevelXP(level))
+		xpNext = float64(s.LevelXP(level + 1))
+	)
+
+	return (float64(xp) - xpCurr) / (xpNext - xpCurr) * 100
+}
+
+// TargetPercentage returns the progress towards the given target level
+// measured from 0 XP
+func (s SkillInfo) TargetPercentage(level int, xp int64) float64 {
+	return s.TargetProgress(level, xp, 0)
+}
+
+// TargetProgress returns the progress towards the given target level
+// measured from the given base XP
+func (s SkillInfo) TargetProgress(level int, xp, baseXP int64) float64 {
+	var xpNext = float64(s.LevelXP(level) - baseXP)
+	if xpNext <= 0 {
+		return 100
+	}
+	return float64(xp-baseXP) / xpNext * 100
+}
+
+// XPToNextLevel returns the XP still missing to reach the next level
+// or zero if the skill is maxed
+func (s SkillInfo) XPToNextLevel(xp int64) int64 {
+	if s.IsMaxed(xp) {
+		return 0
+	}
+
+	level := s.LevelFromXP(xp)
+	return s.LevelXP(level+1) - xp
+}
+
+// XPToTargetLevel returns the XP still missing to reach the given level
+func (s SkillInfo) XPToTargetLevel(level int, xp int64) int64 {
+	return s.LevelXP(level) - xp
+}
+
+func (s SkillInfo) levelTree() map[int]int64 {
+	if s.Elite {
+		return masterLevels
+	}
+	return levels
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// SkillList contains all skills known to RuneMetrics
+var SkillList = []SkillInfo{
+	{
+		ID:    0,
+		Name:  "Attack",
+		Color: "#981414",
+	}, {
+		ID:    1,
+		Name:  "Defence",
+		Color: "#147e98",
+	}, {
+		ID:    2,
+		Name:  "Strength",
+		Color: "#13b787",
+	}, {
+		ID:    3,
+		Name:  "Constitution",
+		Color: "#AACEDA",
+	}, {
+		ID:    4,
+		Name:  "Ranged",
+		Color: "#13b751",
+	}, {
+		ID:    5,
+		Name:  "Prayer",
+		Color: "#6dbff2",
+	}, {
+		ID:    6,
+		Name:  "Magic",
+		Color: "#c3e3dc",
+	}, {
+		ID:    7,
+		Name:  "Cooking",
+		Color: "#553285",
+	}, {
+		ID:    8,
+		Name:  "Woodcutting",
+		Color: "#7e4f35",
+	}, {
+		ID:    9,
+		Name:  "Fletching",
+		Color: "#149893",
+	}, {
+		ID:    10,
+		Name:  "Fishing",
+		Color: "#3e70b9",
+	}, {
+		ID:    11,
+		Name:  "Firemaking",
+		Color: "#f75f28",
+	}, {
+		ID:    12,
+		Name:  "Crafting",
+		Color: "#b6952c",
+	}, {
+		ID:    13,
+		Name:  "Smithing",
+		Color: "#65887e",
+	}, {
+		ID:    14,
+		Name:  "Mining",
+		Color: "#56495e",
+	}, {
+		ID:    15,
+		Name:  "Herblore",
+		Color: "#12453a",
+	}, {
+		ID:    16,
+		Name:  "Agility",
+		Color: "#284A95",
+	}, {
+		ID:    17,
+		Name:  "Thieving",
+		Color: "#36175e",
+	}, {
+		ID:    18,
+		Name:  "Slayer",
+		Color: "#48412f",
+	}, {
+		ID:    19,
+		Name:  "Farming",
+		Color: "#1f7d54",
+	}, {
+		ID:    20,
+		Name:  "Runecrafting",
+		Color: "#d7eba3",
+	}, {
+		ID:    21,
+		Name:  "Hunter",
+		Color: "#c38b4e",
+	}, {
+		ID:    22,
+		Name:  "Construction",
+		Color: "#a8babc",
+	}, {
+		ID:    23,
+		Name:  "Summoning",
+		Color: "#DEA1B0",
+	}, {
+		ID:       24,
+		Name:     "Dungeoneering",
+		Color:    "#723920",
+		MaxLevel: 120,
+	}, {
+		ID:    25,
+		Name:  "Divination",
+		Color: "#943fba",
+	}, {
+		ID:       26,
+		Name:     "Invention",
+		Color:    "#f7b528",
+		MaxLevel: 150,
+		Elite:    true,
+	},
+}
+
+// SkillID is the numeric ID of the skill used in the RuneMetrics API
+type SkillID uint
+
+func (s SkillID) String() string {
+	for _, se := range SkillList {
+		if se.ID == uint(s) {
+			return se.Name
+		}
+	}
+
+	return ""
+}
+
+// Info returns the SkillInfo for the skill
+func (s SkillID) Info() SkillInfo {
+	for _, se := range SkillList {
+		if se.ID == uint(s) {
+			return se
+		}
+	}
+
+	return SkillInfo{}
+}
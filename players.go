@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Luzifer/runemetrics/pkg/history"
+	"github.com/Luzifer/runemetrics/pkg/runemetrics"
+)
+
+// trackedPlayer contains the state of one player tracked by the program
+type trackedPlayer struct {
+	Name string
+
+	// Data is the profile shown in the UI, Cache is the latest profile
+	// fetched which the next fetch is merged with. Both are guarded by
+	// dataLock as the fetch worker accesses them.
+	Data  *runemetrics.PlayerInfo
+	Cache *runemetrics.PlayerInfo
+
+	Goals        []goal
+	GoalsModTime time.Time
+
+	// History is nil when the history is disabled or unavailable
+	History  *history.Store
+	Sessions *history.SessionTracker
+	Rates    history.Rates
+
+	// Gains contains the XP (in tenths) gained per skill in the selected
+	// window, TotalGains the total XP gained in every window
+	Gains      map[runemetrics.SkillID]int64
+	TotalGains map[gainWindow]int64
+
+	Fetching   bool
+	FetchErr   error
+	FetchRetry retryState
+	NextUpdate time.Time
+
+	KnownFeed    time.Time
+	KnownTotalXP int64
+	LastUpdate   map[string]time.Time
+}
+
+var (
+	activePlayer   *trackedPlayer
+	trackedPlayers []*trackedPlayer
+)
+
+// loadPlayer reads the cache and the goals of the player
+func loadPlayer(name string) (*trackedPlayer, error) {
+	t := &trackedPlayer{
+		Name:       name,
+		Gains:      map[runemetrics.SkillID]int64{},
+		TotalGains: map[gainWindow]int64{},
+		LastUpdate: map[string]time.Time{},
+		Sessions:   history.NewSessionTracker(cfg.IdleTime),
+	}
+
+	cache, err := loadCache(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to load cache")
+	}
+	t.Cache = cache.Player
+
+	if err = t.loadGoals(cache.Goals); err != nil {
+		return nil, errors.Wrap(err, "Unable to load goals")
+	}
+
+	return t, nil
+}
+
+// openHistory opens the history of the player, a history which cannot
+// be opened is disabled
+func (t *trackedPlayer) openHistory() {
+	var err error
+	if t.History, err = openHistory(t.Name); err != nil {
+		// History is nice to have but not required to display metrics
+		log.WithError(err).WithField("player", t.Name).Warn("Unable to open history, history is disabled")
+		t.History = nil
+	}
+}
+
+// Close closes the history of the player
+func (t *trackedPlayer) Close() {
+	if t.History != nil {
+		t.History.Close()
+	}
+}
+
+// readRoster reads the player names from a roster file containing one
+// name per line, empty lines and lines starting with # are ignored
+func readRoster(rosterFile string) ([]string, error) {
+	f, err := os.Open(rosterFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to open roster file")
+	}
+	defer f.Close()
+
+	var (
+		names   []string
+		scanner = bufio.NewScanner(f)
+	)
+
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names, errors.Wrap(scanner.Err(), "Unable to read roster file")
+}
+
+// switchPlayer makes the player with the given offset from the active
+// one the active player
+func switchPlayer(offset int) {
+	for i, t := range trackedPlayers {
+		if t != activePlayer {
+			continue
+		}
+
+		n := len(trackedPlayers)
+		activePlayer = trackedPlayers[((i+offset)%n+n)%n]
+		return
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type retryState struct {
+	Attempt int
+	At      time.Time
+}
+
+// next returns the delay until the next attempt using exponential
+// backoff with jitter or false when no more retries should be done
+func (r *retryState) next() (time.Duration, bool) {
+	if r.Attempt >= cfg.RetryMax {
+		r.reset()
+		return 0, false
+	}
+
+	r.Attempt++
+
+	delay := cfg.RetryBaseDelay << uint(r.Attempt-1)
+	if delay <= 0 || delay > cfg.RetryMaxDelay {
+		// delay <= 0 catches overflows of the shift
+		delay = cfg.RetryMaxDelay
+	}
+
+	// Keep half of the delay and randomize the other half to prevent
+	// all clients retrying at the same time
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	r.At = time.Now().Add(delay)
+	return delay, true
+}
+
+func (r *retryState) reset() {
+	r.Attempt = 0
+	r.At = time.Time{}
+}
+
+func (r retryState) String() string {
+	if r.Attempt == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Retry %d/%d at %s", r.Attempt, cfg.RetryMax, r.At.Format("15:04:05"))
+}